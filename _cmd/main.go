@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"wtv"
@@ -20,7 +21,18 @@ func main() {
 
 func run() error {
 
-	err := wtv.Show()
+	debug := flag.Bool("debug", false, "show the debug overlay")
+	level := flag.String("log", "info", "log level(debug, info, warn, error)")
+	flag.Parse()
+
+	l, err := wtv.ParseLevel(*level)
+	if err != nil {
+		return xerrors.Errorf("wtv.ParseLevel() error: %w", err)
+	}
+	wtv.SetLogLevel(l)
+	wtv.SetDebug(*debug)
+
+	err = wtv.Show()
 	if err != nil {
 		return xerrors.Errorf("wtv.Show() error: %w", err)
 	}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"wtv/book"
+	"wtv/config"
+
+	"golang.org/x/xerrors"
+)
+
+// export writes the pages of the book to the cbz in the sort order.
+// The optimized pages are written when the book is optimized.
+func export(args []string) error {
+
+	err := config.Load()
+	if err != nil {
+		return xerrors.Errorf("config.Load() error: %w", err)
+	}
+
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	out := fs.String("o", "", "output file(default is next to the book)")
+	sort := fs.String("sort", config.Get().Sort.String(), "page order(numeric, alphameric, modtime with -desc, none)")
+	original := fs.Bool("original", false, "write the original pages of the optimized book")
+	comicInfo := fs.Bool("comicinfo", config.Get().ExportComicInfo, "write ComicInfo.xml")
+	title := fs.String("title", "", "title of ComicInfo.xml(default is the name of the book)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: wtvtool export [options] path\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return xerrors.Errorf("path is required")
+	}
+	path := fs.Arg(0)
+
+	t, err := config.ParseSortType(*sort)
+	if err != nil {
+		return xerrors.Errorf("config.ParseSortType() error: %w", err)
+	}
+
+	b, err := book.Open(path, *original)
+	if err != nil {
+		return xerrors.Errorf("book.Open() error: %w", err)
+	}
+	b = b.Sorted(t)
+
+	name := *out
+	if name == "" {
+		name = book.ExportPath(path)
+	}
+	if *title == "" {
+		*title = book.ExportTitle(path)
+	}
+
+	opts := book.ExportOptions{Title: *title, ComicInfo: *comicInfo}
+	err = b.ExportCBZ(name, opts, func(done int) {
+		fmt.Fprintf(os.Stdout, "\r%d/%d", done, b.Page())
+	})
+	fmt.Fprintf(os.Stdout, "\n")
+	if err != nil {
+		return xerrors.Errorf("ExportCBZ() error: %w", err)
+	}
+
+	fmt.Fprintf(os.Stdout, "%s\n", name)
+	return nil
+}
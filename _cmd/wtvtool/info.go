@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"wtv/book"
+	"wtv/config"
+
+	"golang.org/x/xerrors"
+)
+
+// info prints the page order, the sizes and the optimize state of the book.
+func info(args []string) error {
+
+	err := config.Load()
+	if err != nil {
+		return xerrors.Errorf("config.Load() error: %w", err)
+	}
+
+	fs := flag.NewFlagSet("info", flag.ExitOnError)
+	width := fs.Int("width", config.Get().Width, "width of the window(default is the config)")
+	scale := fs.Float64("scale", 1, "device scale factor of the display(2 is HiDPI)")
+	js := fs.Bool("json", false, "print as JSON")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: wtvtool info [-width n] [-scale n] [-json] path\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return xerrors.Errorf("path is required")
+	}
+
+	if *scale <= 0 {
+		return xerrors.Errorf("invalid scale[%v]", *scale)
+	}
+
+	//the viewer lays out the pages in the device pixels
+	w := int(math.Ceil(float64(*width) * *scale))
+	bi, err := book.NewInfo(fs.Arg(0), w)
+	if err != nil {
+		return xerrors.Errorf("book.NewInfo() error: %w", err)
+	}
+
+	if *js {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(bi)
+		if err != nil {
+			return xerrors.Errorf("Encode() error: %w", err)
+		}
+		return nil
+	}
+
+	printInfo(bi)
+	return nil
+}
+
+func printInfo(bi *book.Info) {
+
+	w := os.Stdout
+	fmt.Fprintf(w, "path:     %s\n", bi.Path)
+	if bi.Archive {
+		fmt.Fprintf(w, "extract:  %s\n", bi.Dir)
+	}
+	fmt.Fprintf(w, "pages:    %d\n", len(bi.Pages))
+	fmt.Fprintf(w, "length:   %d (width %d)\n", bi.Length, bi.Width)
+	fmt.Fprintf(w, "optimize: %t\n", bi.CanOptimize)
+
+	o := bi.Optimize
+	if o.Exists {
+		fmt.Fprintf(w, "cache:    %s (%d pages, manifest %t, stale %t)\n", o.Dir, o.Pages, o.Manifest, o.Stale)
+	} else {
+		fmt.Fprintf(w, "cache:    none\n")
+	}
+	r := bi.Restitch
+	if r.Exists {
+		fmt.Fprintf(w, "restitch: %s (%d pages, stale %t)\n", r.Dir, r.Pages, r.Stale)
+	}
+
+	fmt.Fprintf(w, "\n")
+	for idx, p := range bi.Pages {
+		if p.Error != "" {
+			fmt.Fprintf(w, "%4d %s error: %s\n", idx+1, p.File, p.Error)
+			continue
+		}
+		fmt.Fprintf(w, "%4d %s %dx%d %s %d\n", idx+1, p.File, p.Width, p.Height, p.Format, p.Size)
+	}
+
+	for _, o := range bi.Orders {
+		mark := ""
+		if o.Sort == bi.Sort {
+			mark = " (current)"
+		}
+		fmt.Fprintf(w, "\n[%s]%s\n", o.Sort, mark)
+		for idx, name := range o.Files {
+			fmt.Fprintf(w, "%4d %s\n", idx+1, name)
+		}
+	}
+}
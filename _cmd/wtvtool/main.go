@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"golang.org/x/xerrors"
+)
+
+// commands are run without the window, they do not use ebiten.
+var commands = map[string]func([]string) error{
+	"verify":   verify,
+	"info":     info,
+	"export":   export,
+	"restitch": restitch,
+}
+
+func main() {
+
+	err := run(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "wtvtool error:\n%+v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+
+	if len(args) == 0 {
+		usage()
+		return xerrors.Errorf("command is required")
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		usage()
+		return xerrors.Errorf("unknown command[%s]", args[0])
+	}
+
+	err := cmd(args[1:])
+	if err != nil {
+		return xerrors.Errorf("%s error: %w", args[0], err)
+	}
+	return nil
+}
+
+func usage() {
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(os.Stderr, "usage: wtvtool command [options] path\n")
+	fmt.Fprintf(os.Stderr, "commands: %v\n", names)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"wtv/book"
+	"wtv/config"
+
+	"golang.org/x/xerrors"
+)
+
+// restitch slices the original pages of the book to the uniform height.
+func restitch(args []string) error {
+
+	err := config.Load()
+	if err != nil {
+		return xerrors.Errorf("config.Load() error: %w", err)
+	}
+
+	fs := flag.NewFlagSet("restitch", flag.ExitOnError)
+	height := fs.Int("height", book.RestitchHeight, "height of the slice")
+	width := fs.Int("width", 0, "width of the slice(default is the widest page)")
+	gutter := fs.Bool("gutter", false, "cut at the blank space between the panels near the height")
+	sort := fs.String("sort", config.Get().Sort.String(), "page order(numeric, alphameric, modtime with -desc, none)")
+	remove := fs.Bool("remove", false, "remove the restitched pages")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: wtvtool restitch [options] path\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return xerrors.Errorf("path is required")
+	}
+	path := fs.Arg(0)
+
+	if *remove {
+		err = book.RemoveRestitch(path)
+		if err != nil {
+			return xerrors.Errorf("book.RemoveRestitch() error: %w", err)
+		}
+		return nil
+	}
+
+	t, err := config.ParseSortType(*sort)
+	if err != nil {
+		return xerrors.Errorf("config.ParseSortType() error: %w", err)
+	}
+
+	b, err := book.Open(path, true)
+	if err != nil {
+		return xerrors.Errorf("book.Open() error: %w", err)
+	}
+	b = b.Sorted(t)
+
+	opts := book.RestitchOptions{Height: *height, Width: *width, Gutter: *gutter}
+	nb, err := b.Restitch(opts, func(done int) {
+		fmt.Fprintf(os.Stdout, "\r%d/%d", done, b.Page())
+	})
+	fmt.Fprintf(os.Stdout, "\n")
+	if err != nil {
+		return xerrors.Errorf("Restitch() error: %w", err)
+	}
+
+	fmt.Fprintf(os.Stdout, "%d pages to %d pages\n", b.Page(), nb.Page())
+	return nil
+}
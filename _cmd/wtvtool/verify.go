@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"wtv/book"
+
+	"golang.org/x/xerrors"
+)
+
+// verify decodes the images and the archives of the books or the library.
+func verify(args []string) error {
+
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	verbose := fs.Bool("v", false, "print the readable files")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: wtvtool verify [-v] path...\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return xerrors.Errorf("path is required")
+	}
+
+	files, problems := 0, 0
+	for _, path := range fs.Args() {
+		err := book.Verify(path, func(name string, err error) {
+			files++
+			if err != nil {
+				problems++
+				fmt.Fprintf(os.Stdout, "NG %s: %v\n", name, err)
+			} else if *verbose {
+				fmt.Fprintf(os.Stdout, "OK %s\n", name)
+			}
+		})
+		if err != nil {
+			return xerrors.Errorf("book.Verify(%s) error: %w", path, err)
+		}
+	}
+
+	fmt.Fprintf(os.Stdout, "%d files, %d problems\n", files, problems)
+	if problems > 0 {
+		return xerrors.Errorf("%d problems found", problems)
+	}
+	return nil
+}
@@ -98,31 +98,20 @@ const defaultFontSize = 20
 
 func initFont() error {
 
-	f, err := assets.Open(defaultFontName)
+	data, err := fs.ReadFile(assets, defaultFontName)
 	if err != nil {
-		return xerrors.Errorf("assets.Open() error: %w", err)
+		return xerrors.Errorf("fs.ReadFile() error: %w", err)
 	}
 
-	info, err := f.Stat()
+	f, err := opentype.Parse(data)
 	if err != nil {
-		return xerrors.Errorf("file.Stat() error: %w", err)
+		return xerrors.Errorf("opentype.Parse() error: %w", err)
 	}
 
-	sz := info.Size()
-	data := make([]byte, sz)
-	_, err = f.Read(data)
+	fonts = NewFontManager(f)
+	err = setFontSize(defaultFontSize)
 	if err != nil {
-		return xerrors.Errorf("file.Read() error: %w", err)
+		return xerrors.Errorf("setFontSize() error: %w", err)
 	}
-
-	font, err := opentype.Parse(data)
-	if err != nil {
-		return xerrors.Errorf("file.Read() error: %w", err)
-	}
-
-	defaultFont, err = opentype.NewFace(font, &opentype.FaceOptions{
-		Size: defaultFontSize,
-		DPI:  defaultFontDPI,
-	})
 	return nil
 }
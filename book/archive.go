@@ -0,0 +1,229 @@
+package book
+
+import (
+	"archive/zip"
+	"crypto/sha1"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"wtv/config"
+
+	"golang.org/x/xerrors"
+)
+
+const ArchiveDirectory = "archive"
+
+var archiveExts = []string{".zip", ".cbz"}
+var imageExts = []string{".jpg", ".jpeg", ".png", ".gif", ".bmp", ".webp"}
+
+func hasExt(name string, exts []string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, elm := range exts {
+		if ext == elm {
+			return true
+		}
+	}
+	return false
+}
+
+func IsArchive(name string) bool {
+	return hasExt(name, archiveExts)
+}
+
+func isImage(name string) bool {
+	return hasExt(name, imageExts)
+}
+
+// extractArchive extracts the images of the archive into the cache directory
+// and returns the directory. Extracted directory is reused while the archive is not updated.
+func extractArchive(name string) (string, error) {
+
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return "", xerrors.Errorf("filepath.Abs() error: %w", err)
+	}
+
+	info, err := os.Stat(abs)
+	if err != nil {
+		return "", xerrors.Errorf("os.Stat() error: %w", err)
+	}
+
+	dir := filepath.Join(config.CacheDir(), ArchiveDirectory,
+		fmt.Sprintf("%x", sha1.Sum([]byte(abs))))
+
+	if dinfo, err := os.Stat(dir); err == nil {
+		if !dinfo.ModTime().Before(info.ModTime()) {
+			return dir, nil
+		}
+		err = os.RemoveAll(dir)
+		if err != nil {
+			return "", xerrors.Errorf("os.RemoveAll() error: %w", err)
+		}
+	}
+
+	r, err := zip.OpenReader(abs)
+	if err != nil {
+		return "", xerrors.Errorf("zip.OpenReader() error: %w", err)
+	}
+	defer r.Close()
+
+	//extract to work directory, then rename(half extracted directory is not used)
+	work := dir + ".work"
+	err = os.RemoveAll(work)
+	if err != nil {
+		return "", xerrors.Errorf("os.RemoveAll() error: %w", err)
+	}
+	err = os.MkdirAll(work, 0777)
+	if err != nil {
+		return "", xerrors.Errorf("os.MkdirAll() error: %w", err)
+	}
+
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() || !isImage(f.Name) {
+			continue
+		}
+
+		dst := filepath.Join(work, filepath.FromSlash(f.Name))
+		if !strings.HasPrefix(dst, work+string(os.PathSeparator)) {
+			return "", xerrors.Errorf("invalid archive entry[%s]", f.Name)
+		}
+
+		err = extractFile(f, dst)
+		if err != nil {
+			return "", xerrors.Errorf("extractFile() error: %w", err)
+		}
+	}
+
+	err = os.Rename(work, dir)
+	if err != nil {
+		return "", xerrors.Errorf("os.Rename() error: %w", err)
+	}
+	return dir, nil
+}
+
+func extractFile(f *zip.File, dst string) error {
+
+	err := os.MkdirAll(filepath.Dir(dst), 0777)
+	if err != nil {
+		return xerrors.Errorf("os.MkdirAll() error: %w", err)
+	}
+
+	src, err := f.Open()
+	if err != nil {
+		return xerrors.Errorf("zip.File Open() error: %w", err)
+	}
+	defer src.Close()
+
+	fp, err := os.Create(dst)
+	if err != nil {
+		return xerrors.Errorf("os.Create() error: %w", err)
+	}
+	defer fp.Close()
+
+	_, err = io.Copy(fp, src)
+	if err != nil {
+		return xerrors.Errorf("io.Copy() error: %w", err)
+	}
+	return nil
+}
+
+// loadArchiveCover decodes the first image(by name) of the archive.
+func loadArchiveCover(name string) (image.Image, error) {
+
+	r, err := zip.OpenReader(name)
+	if err != nil {
+		return nil, xerrors.Errorf("zip.OpenReader() error: %w", err)
+	}
+	defer r.Close()
+
+	var files []*zip.File
+	for _, f := range r.File {
+		if !f.FileInfo().IsDir() && isImage(f.Name) {
+			files = append(files, f)
+		}
+	}
+	if len(files) == 0 {
+		return nil, xerrors.Errorf("image not found[%s]", name)
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Name < files[j].Name
+	})
+
+	fp, err := files[0].Open()
+	if err != nil {
+		return nil, xerrors.Errorf("zip.File Open() error: %w", err)
+	}
+	defer fp.Close()
+
+	img, _, err := image.Decode(fp)
+	if err != nil {
+		return nil, xerrors.Errorf("image.Decode() error: %w", err)
+	}
+	return img, nil
+}
+
+// findCover returns the first image of the directory.
+// If there is no image, the first sub directory is searched.
+func findCover(dir string) (string, error) {
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", xerrors.Errorf("os.ReadDir() error: %w", err)
+	}
+
+	var files []string
+	var dirs []string
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		if entry.IsDir() {
+			dirs = append(dirs, path)
+		} else if isImage(path) {
+			files = append(files, path)
+		}
+	}
+
+	if len(files) > 0 {
+		conf := config.Get()
+		sort.Slice(files, conf.Sort.Less(files))
+		return files[0], nil
+	}
+
+	sort.Strings(dirs)
+	for _, d := range dirs {
+		name, err := findCover(d)
+		if err == nil {
+			return name, nil
+		}
+	}
+	return "", xerrors.Errorf("image not found[%s]", dir)
+}
+
+func LoadCover(name string) (image.Image, error) {
+
+	if IsArchive(name) {
+		img, err := loadArchiveCover(name)
+		if err != nil {
+			return nil, xerrors.Errorf("loadArchiveCover() error: %w", err)
+		}
+		return img, nil
+	}
+
+	cover, err := findCover(name)
+	if err != nil {
+		return nil, xerrors.Errorf("findCover() error: %w", err)
+	}
+
+	img, err := Load(cover)
+	if err != nil {
+		return nil, xerrors.Errorf("Load() error: %w", err)
+	}
+	return img, nil
+}
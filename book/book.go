@@ -0,0 +1,560 @@
+package book
+
+import (
+	"encoding/gob"
+	"fmt"
+	"image"
+	"image/draw"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"wtv/config"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	OptimizeDirectory = ".wtv_optimize"
+	OptimizeManifest  = ".wtv_manifest.gob"
+	//height (65536) must be less than or equal to 32768
+	//TODO  -2 means -1 is ebiten error
+	OpenGLHeight   = 1<<(16-1) - 2
+	OptimizeHeight = 1 << 11           //2048
+	OptimizeLimit  = OpenGLHeight >> 2 // 9
+)
+
+// Warn logs the errors the book skips with the pairs of the key and the value.
+// The viewer replaces it with its logger.
+var Warn = func(msg string, kv ...interface{}) {
+	fmt.Fprintln(os.Stderr, append([]interface{}{msg}, kv...)...)
+}
+
+type Book struct {
+	dir      string
+	files    []string
+	optimize bool
+	doc      *Document
+
+	//optimized page to the source page
+	sources map[string]string
+
+	problems *Problems
+}
+
+func New(dir string) (*Book, error) {
+
+	var b Book
+	b.optimize = false
+	b.dir = dir
+	b.problems = NewProblems()
+	files, err := getFiles(dir)
+	if err != nil {
+		return nil, xerrors.Errorf("getFiles() error: %w", err)
+	}
+	if len(files) == 0 {
+		return nil, xerrors.Errorf("page not found[%s]", dir)
+	}
+
+	switch filepath.Base(dir) {
+	case OptimizeDirectory, RestitchDirectory:
+		b.optimize = true
+		b.sources = loadSources(dir, files)
+	}
+
+	b.files = files
+	b.files = b.sort(config.Get().Sort)
+	b.measure()
+	return &b, nil
+}
+
+// Open reads the directory or the archive like the viewer.
+// The restitch or the optimize directory is used when it exists and original is false.
+func Open(path string, original bool) (*Book, error) {
+
+	dir := path
+	if IsArchive(path) {
+		ad, err := extractArchive(path)
+		if err != nil {
+			return nil, xerrors.Errorf("extractArchive() error: %w", err)
+		}
+		dir = ad
+	}
+
+	opti := false
+	if !original && existsRestitchDirectory(dir) {
+		dir = filepath.Join(dir, RestitchDirectory)
+		opti = true
+	} else if !original && existsOptimizeDirectory(dir) {
+		dir = filepath.Join(dir, OptimizeDirectory)
+		opti = true
+	}
+
+	b, err := New(dir)
+	if err != nil {
+		return nil, xerrors.Errorf("New() error: %w", err)
+	}
+	b.optimize = opti
+	return b, nil
+}
+
+// Sorted returns the book in the order.
+// The pages of the optimized book are sorted by the source pages.
+func (b *Book) Sorted(t config.SortType) *Book {
+	nb := *b
+	nb.files = b.sort(t)
+	nb.measure()
+	return &nb
+}
+
+func (b *Book) sort(t config.SortType) []string {
+	if filepath.Base(b.dir) == RestitchDirectory {
+		//the slices of the strip are in the order of the number by any sort
+		return sortTiles(append([]string(nil), b.files...))
+	}
+	return sortFiles(b.files, b.sources, t)
+}
+
+// Index returns the index of the file or -1.
+func (b *Book) Index(name string) int {
+	for idx, f := range b.files {
+		if f == name {
+			return idx
+		}
+	}
+	return -1
+}
+
+func sortFiles(files []string, sources map[string]string, t config.SortType) []string {
+
+	if sources == nil {
+		rtn := append([]string(nil), files...)
+		sort.Slice(rtn, t.Less(rtn))
+		return rtn
+	}
+
+	//the divided pages are kept together
+	groups := make(map[string][]string)
+	var keys []string
+	for _, f := range files {
+		src := sources[f]
+		if _, ok := groups[src]; !ok {
+			keys = append(keys, src)
+		}
+		groups[src] = append(groups[src], f)
+	}
+	sort.Slice(keys, t.Less(keys))
+
+	rtn := make([]string, 0, len(files))
+	for _, k := range keys {
+		rtn = append(rtn, sortTiles(groups[k])...)
+	}
+	return rtn
+}
+
+// sortTiles sorts the divided pages by the number("name_100.jpg" is after "name_99.jpg").
+func sortTiles(files []string) []string {
+	sort.SliceStable(files, func(i, j int) bool {
+		return tileNumber(files[i]) < tileNumber(files[j])
+	})
+	return files
+}
+
+// tileNumber is the number of the divided page("name_12.jpg" and the slice "00012.jpg" are 12).
+func tileNumber(f string) int {
+	name := filepath.Base(f)
+	name = name[:len(name)-len(filepath.Ext(name))]
+	if idx := strings.LastIndex(name, "_"); idx != -1 {
+		name = name[idx+1:]
+	}
+	n, err := strconv.Atoi(name)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// loadSources reads the manifest of the optimize or the restitch directory.
+// The manifest is keyed by the path in the directory.
+// Without the manifest, the source is guessed by the path("ch1/name_00.jpg" is "../ch1/name.jpg").
+func loadSources(dir string, files []string) map[string]string {
+
+	manifest := make(map[string]string)
+	fp, err := os.Open(filepath.Join(dir, OptimizeManifest))
+	if err == nil {
+		err = gob.NewDecoder(fp).Decode(&manifest)
+		if err != nil {
+			Warn("manifest decode error", "dir", dir, "err", err)
+		}
+		fp.Close()
+	}
+
+	sources := make(map[string]string)
+	for _, f := range files {
+		rel := relPath(dir, f)
+		if src, ok := manifest[rel]; ok {
+			sources[f] = src
+			continue
+		}
+		ext := filepath.Ext(rel)
+		name := rel[:len(rel)-len(ext)]
+		if idx := strings.LastIndex(name, "_"); idx > strings.LastIndex(name, string(filepath.Separator)) {
+			name = name[:idx]
+		}
+		sources[f] = filepath.Join(filepath.Dir(dir), name+ext)
+	}
+	return sources
+}
+
+func writeManifest(dir string, sources map[string]string) error {
+
+	manifest := make(map[string]string)
+	for f, src := range sources {
+		manifest[relPath(dir, f)] = src
+	}
+
+	fp, err := os.Create(filepath.Join(dir, OptimizeManifest))
+	if err != nil {
+		return xerrors.Errorf("os.Create() error: %w", err)
+	}
+	defer fp.Close()
+
+	err = gob.NewEncoder(fp).Encode(manifest)
+	if err != nil {
+		return xerrors.Errorf("Encode() error: %w", err)
+	}
+	return nil
+}
+
+// measure makes the Document from the image headers.
+// A page that can not be read is estimated until it is loaded.
+func (b *Book) measure() {
+	b.doc = NewDocument(len(b.files))
+	for idx := range b.files {
+		info, err := b.Info(idx)
+		if err != nil {
+			continue
+		}
+		b.doc.SetSize(idx, info.Width, info.Height)
+	}
+
+	err := metadata.Save()
+	if err != nil {
+		Warn("metadata save error", "err", err)
+	}
+}
+
+func (b *Book) Page() int {
+	return len(b.files)
+}
+
+// File is the path of the page, it is empty out of the range.
+func (b *Book) File(idx int) string {
+	if idx < 0 || idx >= len(b.files) {
+		return ""
+	}
+	return b.files[idx]
+}
+
+// Name is the path of the page in the book.
+func (b *Book) Name(idx int) string {
+	return b.rel(b.File(idx))
+}
+
+func (b *Book) Document() *Document {
+	return b.doc
+}
+
+// Chapters returns the first page index of each chapter.
+// A chapter is a run of pages in the same directory,
+// the directory of the source page is used for the optimized pages.
+func (b *Book) Chapters() []int {
+	var rtn []int
+	prev := ""
+	for idx, name := range b.files {
+		if src, ok := b.sources[name]; ok {
+			name = src
+		}
+		d := filepath.Dir(name)
+		if idx == 0 || d != prev {
+			rtn = append(rtn, idx)
+		}
+		prev = d
+	}
+	return rtn
+}
+
+// Chapter returns the first page index of the chapter containing idx.
+func (b *Book) Chapter(idx int) int {
+	rtn := 0
+	for _, c := range b.Chapters() {
+		if c > idx {
+			break
+		}
+		rtn = c
+	}
+	return rtn
+}
+
+var IndexError = fmt.Errorf("Book Index Error")
+
+// Info returns the header information of the page without decoding.
+func (b *Book) Info(idx int) (PageInfo, error) {
+	if idx < 0 || idx >= len(b.files) {
+		return PageInfo{}, IndexError
+	}
+	info, err := metadata.Get(b.files[idx])
+	if err != nil {
+		return PageInfo{}, xerrors.Errorf("metadata.Get() error: %w", err)
+	}
+	return info, nil
+}
+
+func (b *Book) Load(idx int) (image.Image, error) {
+
+	if idx < 0 || idx >= len(b.files) {
+		return nil, IndexError
+	}
+
+	img, err := Load(b.files[idx])
+	if err != nil {
+		b.problems.Add(b.files[idx], err)
+		return nil, xerrors.Errorf("Load() error: %w", err)
+	}
+
+	bo := img.Bounds()
+	b.doc.SetSize(idx, bo.Dx(), bo.Dy())
+	return img, nil
+}
+
+// ProblemCount is the number of the pages failed to load.
+func (b *Book) ProblemCount() int {
+	return b.problems.Len()
+}
+
+// Problems are the pages failed to load in the page order.
+func (b *Book) Problems() []Problem {
+	var rtn []Problem
+	for idx, name := range b.files {
+		if err := b.problems.Get(name); err != nil {
+			rtn = append(rtn, Problem{idx, name, err})
+		}
+	}
+	return rtn
+}
+
+// Check decodes all the pages in the background to find the problems.
+// fn is called with the number of the checked pages.
+func (b *Book) Check(fn func(done int)) {
+	for idx, name := range b.files {
+		err := VerifyFile(name)
+		if err != nil {
+			b.problems.Add(name, err)
+		}
+		fn(idx + 1)
+	}
+}
+
+func (b *Book) String() string {
+	return fmt.Sprintf("%v", b.files)
+}
+
+// CanOptimize reports whether a page is too long at the width.
+func (b *Book) CanOptimize(w, h int) bool {
+
+	if b.optimize {
+		return false
+	}
+
+	for idx := range b.files {
+		info, err := b.Info(idx)
+		if err != nil || info.Width == 0 {
+			continue
+		}
+		s := float64(w) / float64(info.Width)
+		nowH := float64(info.Height) * s
+
+		if nowH > OptimizeLimit {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *Book) Optimize(w, h int) (*Book, error) {
+
+	path := filepath.Join(b.dir, OptimizeDirectory)
+	err := os.Mkdir(path, 0777)
+	if err != nil {
+		return nil, xerrors.Errorf("already exitst?[%s]", path)
+	}
+
+	var newB Book
+	newB.optimize = true
+	newB.dir = path
+	newB.sources = make(map[string]string)
+	newB.problems = NewProblems()
+
+	for fidx, name := range b.files {
+
+		//the pages of the chapters are kept in the same directories("ch1/001.jpg" is "ch1/001_00.jpg")
+		rel := b.rel(name)
+		nn := filepath.Join(path, rel[:len(rel)-len(filepath.Ext(rel))])
+		err = os.MkdirAll(filepath.Dir(nn), 0777)
+		if err != nil {
+			return nil, xerrors.Errorf("os.MkdirAll() error: %w", err)
+		}
+
+		copyPage := func() error {
+			fn := nn + "_00" + filepath.Ext(name)
+			err := copyFile(name, fn)
+			if err != nil {
+				return xerrors.Errorf("copyFile() error: %w", err)
+			}
+			newB.files = append(newB.files, fn)
+			newB.sources[fn] = name
+			return nil
+		}
+
+		div := 0
+		info, err := b.Info(fidx)
+		if err == nil && info.Width > 0 {
+			s := float64(w) / float64(info.Width)
+			nowH := float64(info.Height) * s
+			div = int(nowH / OptimizeHeight)
+		}
+
+		//short page is copied without decoding,
+		//the broken page is copied to be shown as the placeholder
+		if div <= 1 {
+			err := copyPage()
+			if err != nil {
+				return nil, xerrors.Errorf("copyPage() error: %w", err)
+			}
+			continue
+		}
+
+		img, err := Load(name)
+		if err != nil {
+			Warn("optimize page error", "file", name, "err", err)
+			err = copyPage()
+			if err != nil {
+				return nil, xerrors.Errorf("copyPage() error: %w", err)
+			}
+			continue
+		}
+		bou := img.Bounds()
+
+		divH := bou.Dy() / div
+		modH := bou.Dy() % div
+
+		cutH := 0
+
+		for idx := 0; idx < div; idx++ {
+
+			flg := false
+			if idx == div-1 {
+				divH += modH
+				flg = true
+			}
+
+			r := image.Rect(0, 0, bou.Dx(), divH)
+			newImg := image.NewRGBA(r)
+			draw.Draw(newImg, r, img, image.Point{0, cutH}, draw.Src)
+
+			fn := fmt.Sprintf("%s_%02d.jpg", nn, idx)
+			err := WriteImage(fn, newImg)
+			if err != nil {
+				return nil, xerrors.Errorf("WriteImage() error: %w", err)
+			}
+
+			newB.files = append(newB.files, fn)
+			newB.sources[fn] = name
+
+			if flg {
+				break
+			}
+			cutH += divH
+		}
+	}
+
+	err = writeManifest(path, newB.sources)
+	if err != nil {
+		return nil, xerrors.Errorf("writeManifest() error: %w", err)
+	}
+
+	newB.measure()
+	return &newB, nil
+}
+
+func copyFile(src, dst string) error {
+
+	in, err := os.Open(src)
+	if err != nil {
+		return xerrors.Errorf("os.Open() error: %w", err)
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return xerrors.Errorf("os.Create() error: %w", err)
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, in)
+	if err != nil {
+		return xerrors.Errorf("io.Copy() error: %w", err)
+	}
+	return nil
+}
+
+// relPath is the path of the file in the directory.
+func relPath(dir, name string) string {
+	if r, err := filepath.Rel(dir, name); err == nil {
+		return r
+	}
+	return name
+}
+
+func getFiles(dir string) ([]string, error) {
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, xerrors.Errorf("os.ReadDir() error: %w", err)
+	}
+
+	var rtn []string
+	for _, entry := range entries {
+
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		if entry.IsDir() {
+			files, err := getFiles(path)
+			if err != nil {
+				return nil, xerrors.Errorf("getFiles(r) error: %w", err)
+			}
+			rtn = append(rtn, files...)
+		} else if isImage(path) {
+			//ComicInfo.xml, Thumbs.db... are not the pages
+			rtn = append(rtn, path)
+		}
+	}
+
+	conf := config.Get()
+	sort.Slice(rtn, conf.Sort.Less(rtn))
+
+	return rtn, nil
+}
+
+func existsOptimizeDirectory(dir string) bool {
+	path := filepath.Join(dir, OptimizeDirectory)
+	if _, err := os.Stat(path); err == nil {
+		return true
+	}
+	return false
+}
@@ -0,0 +1,186 @@
+package book
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"wtv/config"
+)
+
+// setup keeps the cache of the test in the temporary directory.
+func setup(t *testing.T, s config.SortType) {
+	conf := config.Get()
+	cache, sort := conf.CacheDirectory, conf.Sort
+	t.Cleanup(func() {
+		conf.CacheDirectory, conf.Sort = cache, sort
+	})
+	conf.CacheDirectory = t.TempDir()
+	conf.Sort = s
+}
+
+func writePage(t *testing.T, name string) {
+	writeImage(t, name, 10, 20)
+}
+
+func writeImage(t *testing.T, name string, w, h int) {
+	err := os.MkdirAll(filepath.Dir(name), 0777)
+	if err != nil {
+		t.Fatalf("os.MkdirAll() error: %v", err)
+	}
+	err = WriteImage(name, image.NewRGBA(image.Rect(0, 0, w, h)))
+	if err != nil {
+		t.Fatalf("WriteImage() error: %v", err)
+	}
+}
+
+func TestRestitchOrder(t *testing.T) {
+
+	setup(t, config.NumericSortDesc)
+
+	dir := filepath.Join(t.TempDir(), RestitchDirectory)
+	var want []string
+	for idx := 1; idx <= 12; idx++ {
+		name := filepath.Join(dir, fmt.Sprintf("%05d.jpg", idx))
+		writePage(t, name)
+		want = append(want, name)
+	}
+
+	b, err := New(dir)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	for st := config.NumericSortAsc; st <= config.DoNotSort; st++ {
+		sorted := b.Sorted(st)
+		for idx, name := range want {
+			if got := sorted.File(idx); got != name {
+				t.Errorf("%s: page %d want %s got %s", st, idx, filepath.Base(name), filepath.Base(got))
+			}
+		}
+	}
+}
+
+func TestGetFilesImageOnly(t *testing.T) {
+
+	setup(t, config.NumericSortAsc)
+
+	dir := t.TempDir()
+	writePage(t, filepath.Join(dir, "001.jpg"))
+	writePage(t, filepath.Join(dir, "sub", "002.JPG"))
+	for _, name := range []string{"ComicInfo.xml", "Thumbs.db", "notes.txt"} {
+		err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0666)
+		if err != nil {
+			t.Fatalf("os.WriteFile() error: %v", err)
+		}
+	}
+
+	b, err := New(dir)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	if b.Page() != 2 {
+		t.Fatalf("pages want 2 got %d: %v", b.Page(), b)
+	}
+
+	//verify and the viewer agree
+	problems := 0
+	err = Verify(dir, func(name string, err error) {
+		if err != nil {
+			problems++
+		}
+	})
+	if err != nil {
+		t.Fatalf("Verify() error: %v", err)
+	}
+	b.Check(func(int) {})
+	if len(b.Problems()) != problems {
+		t.Errorf("problems want %d got %v", problems, b.Problems())
+	}
+}
+
+// newChapterBook is ch1 and ch2 of the same file names, "001.jpg" is the long page.
+func newChapterBook(t *testing.T) *Book {
+	dir := t.TempDir()
+	for _, ch := range []string{"ch1", "ch2"} {
+		writeImage(t, filepath.Join(dir, ch, "001.jpg"), 10, 100)
+		writePage(t, filepath.Join(dir, ch, "002.jpg"))
+	}
+	b, err := New(dir)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	if want := []int{0, 2}; !reflect.DeepEqual(b.Chapters(), want) {
+		t.Fatalf("chapters want %v got %v", want, b.Chapters())
+	}
+	return b
+}
+
+func TestOptimizeChapters(t *testing.T) {
+
+	//the numeric sort mixes the chapters of the same names
+	setup(t, config.AlphamericSortAsc)
+
+	b := newChapterBook(t)
+	ob, err := b.Optimize(1000, 0)
+	if err != nil {
+		t.Fatalf("Optimize() error: %v", err)
+	}
+
+	//"001.jpg" is divided to 4 tiles in each chapter
+	want := []int{0, 5}
+	if ob.Page() != 10 {
+		t.Fatalf("pages want 10 got %d: %v", ob.Page(), ob)
+	}
+	if !reflect.DeepEqual(ob.Chapters(), want) {
+		t.Errorf("chapters want %v got %v", want, ob.Chapters())
+	}
+
+	dir := filepath.Join(b.dir, OptimizeDirectory)
+	nb, err := New(dir)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	if nb.Page() != 10 || !reflect.DeepEqual(nb.Chapters(), want) {
+		t.Errorf("reopened pages %d chapters want %v got %v", nb.Page(), want, nb.Chapters())
+	}
+
+	//the sources are guessed by the path without the manifest
+	err = os.Remove(filepath.Join(dir, OptimizeManifest))
+	if err != nil {
+		t.Fatalf("os.Remove() error: %v", err)
+	}
+	nb, err = New(dir)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	if !reflect.DeepEqual(nb.Chapters(), want) {
+		t.Errorf("without manifest chapters want %v got %v", want, nb.Chapters())
+	}
+}
+
+func TestRestitchChapters(t *testing.T) {
+
+	setup(t, config.AlphamericSortAsc)
+
+	dir := t.TempDir()
+	for _, ch := range []string{"ch1", "ch2"} {
+		writePage(t, filepath.Join(dir, ch, "001.jpg"))
+		writePage(t, filepath.Join(dir, ch, "002.jpg"))
+	}
+	b, err := New(dir)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	//the strip of 80 rows is sliced at 0, 30 and 60, ch2 starts at 40
+	rb, err := b.Restitch(RestitchOptions{Height: 30, Width: 10}, nil)
+	if err != nil {
+		t.Fatalf("Restitch() error: %v", err)
+	}
+	want := []int{0, 2}
+	if rb.Page() != 3 || !reflect.DeepEqual(rb.Chapters(), want) {
+		t.Errorf("pages %d chapters want %v got %v", rb.Page(), want, rb.Chapters())
+	}
+}
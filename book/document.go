@@ -0,0 +1,118 @@
+package book
+
+import (
+	"sync"
+)
+
+// Document is the coordinate of the pages laid out vertically.
+// Each page is fit to the width, so a global offset is
+// proportional to the width and the same model is shared by the Viewer and the ScrollMenu.
+type Document struct {
+	mutex sync.Mutex
+	//height / width, zero is not measured
+	ratios []float64
+}
+
+func NewDocument(pages int) *Document {
+	var d Document
+	d.ratios = make([]float64, pages)
+	return &d
+}
+
+func (d *Document) Pages() int {
+	return len(d.ratios)
+}
+
+// SetSize records the source size of the page.
+func (d *Document) SetSize(idx int, w, h int) {
+	if idx < 0 || idx >= len(d.ratios) || w <= 0 {
+		return
+	}
+	d.mutex.Lock()
+	d.ratios[idx] = float64(h) / float64(w)
+	d.mutex.Unlock()
+}
+
+// Measured reports whether the size of the page is known.
+func (d *Document) Measured(idx int) bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return idx >= 0 && idx < len(d.ratios) && d.ratios[idx] > 0
+}
+
+// estimate is the average of the measured pages
+func (d *Document) estimate() float64 {
+	sum := 0.0
+	n := 0
+	for _, r := range d.ratios {
+		if r > 0 {
+			sum += r
+			n++
+		}
+	}
+	if n == 0 {
+		return 1.0
+	}
+	return sum / float64(n)
+}
+
+func (d *Document) ratio(idx int, est float64) float64 {
+	if r := d.ratios[idx]; r > 0 {
+		return r
+	}
+	return est
+}
+
+// PageHeight is the height of the page at the width.
+func (d *Document) PageHeight(idx, width int) int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if idx < 0 || idx >= len(d.ratios) {
+		return 0
+	}
+	return int(d.ratio(idx, d.estimate()) * float64(width))
+}
+
+// Length is the height of all pages at the width.
+func (d *Document) Length(width int) int {
+	return d.Offset(len(d.ratios), 0, width)
+}
+
+// Offset converts the page and the position in the page to the global offset.
+func (d *Document) Offset(idx, pos, width int) int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	est := d.estimate()
+	off := 0
+	for i := 0; i < idx && i < len(d.ratios); i++ {
+		off += int(d.ratio(i, est) * float64(width))
+	}
+	return off + pos
+}
+
+// Locate converts the global offset to the page and the position in the page.
+func (d *Document) Locate(off, width int) (int, int) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if len(d.ratios) == 0 {
+		return 0, 0
+	}
+	if off < 0 {
+		return 0, off
+	}
+
+	est := d.estimate()
+	top := 0
+	for idx := range d.ratios {
+		h := int(d.ratio(idx, est) * float64(width))
+		if off < top+h {
+			return idx, off - top
+		}
+		top += h
+	}
+
+	last := len(d.ratios) - 1
+	return last, off - (top - int(d.ratio(last, est)*float64(width)))
+}
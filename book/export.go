@@ -0,0 +1,195 @@
+package book
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	ComicInfoName  = "ComicInfo.xml"
+	ExportMinDigit = 3
+)
+
+// ExportOptions is the option of ExportCBZ.
+type ExportOptions struct {
+	Title     string
+	ComicInfo bool
+}
+
+// comicInfo is the subset of the ComicInfo.xml schema.
+type comicInfo struct {
+	XMLName   xml.Name        `xml:"ComicInfo"`
+	XSI       string          `xml:"xmlns:xsi,attr"`
+	XSD       string          `xml:"xmlns:xsd,attr"`
+	Title     string          `xml:"Title,omitempty"`
+	PageCount int             `xml:"PageCount"`
+	Pages     []comicInfoPage `xml:"Pages>Page"`
+}
+
+type comicInfoPage struct {
+	Image       int   `xml:"Image,attr"`
+	ImageSize   int64 `xml:"ImageSize,attr,omitempty"`
+	ImageWidth  int   `xml:"ImageWidth,attr,omitempty"`
+	ImageHeight int   `xml:"ImageHeight,attr,omitempty"`
+}
+
+// ExportCBZ writes the pages in the current order to the archive.
+// The files are copied one by one without decoding, the memory does not grow with the book.
+// progress is called with the number of the written pages, it may be nil.
+func (b *Book) ExportCBZ(name string, opts ExportOptions, progress func(done int)) error {
+
+	//the half written file is not left with the name
+	work := name + ".work"
+	fp, err := os.Create(work)
+	if err != nil {
+		return xerrors.Errorf("os.Create() error: %w", err)
+	}
+
+	err = b.writeCBZ(fp, opts, progress)
+	cerr := fp.Close()
+	if err == nil && cerr != nil {
+		err = xerrors.Errorf("Close() error: %w", cerr)
+	}
+	if err != nil {
+		os.Remove(work)
+		return xerrors.Errorf("writeCBZ() error: %w", err)
+	}
+
+	err = os.Rename(work, name)
+	if err != nil {
+		os.Remove(work)
+		return xerrors.Errorf("os.Rename() error: %w", err)
+	}
+	return nil
+}
+
+func (b *Book) writeCBZ(w io.Writer, opts ExportOptions, progress func(int)) error {
+
+	zw := zip.NewWriter(w)
+
+	digit := len(strconv.Itoa(len(b.files)))
+	if digit < ExportMinDigit {
+		digit = ExportMinDigit
+	}
+
+	info := comicInfo{
+		XSI:       "http://www.w3.org/2001/XMLSchema-instance",
+		XSD:       "http://www.w3.org/2001/XMLSchema",
+		Title:     opts.Title,
+		PageCount: len(b.files),
+	}
+
+	for idx, src := range b.files {
+
+		entry := fmt.Sprintf("%0*d%s", digit, idx+1, strings.ToLower(filepath.Ext(src)))
+		size, err := copyEntry(zw, entry, src)
+		if err != nil {
+			return xerrors.Errorf("copyEntry(%s) error: %w", src, err)
+		}
+
+		page := comicInfoPage{Image: idx, ImageSize: size}
+		if pi, err := b.Info(idx); err == nil {
+			page.ImageWidth, page.ImageHeight = pi.Width, pi.Height
+		}
+		info.Pages = append(info.Pages, page)
+
+		if progress != nil {
+			progress(idx + 1)
+		}
+	}
+
+	if opts.ComicInfo {
+		err := writeComicInfo(zw, &info)
+		if err != nil {
+			return xerrors.Errorf("writeComicInfo() error: %w", err)
+		}
+	}
+
+	err := zw.Close()
+	if err != nil {
+		return xerrors.Errorf("zip Close() error: %w", err)
+	}
+	return nil
+}
+
+// copyEntry stores the image, it is compressed already.
+func copyEntry(zw *zip.Writer, entry, src string) (int64, error) {
+
+	fp, err := os.Open(src)
+	if err != nil {
+		return 0, xerrors.Errorf("os.Open() error: %w", err)
+	}
+	defer fp.Close()
+
+	fi, err := fp.Stat()
+	if err != nil {
+		return 0, xerrors.Errorf("Stat() error: %w", err)
+	}
+
+	hdr := &zip.FileHeader{Name: entry, Method: zip.Store}
+	hdr.Modified = fi.ModTime()
+	w, err := zw.CreateHeader(hdr)
+	if err != nil {
+		return 0, xerrors.Errorf("CreateHeader() error: %w", err)
+	}
+
+	n, err := io.Copy(w, fp)
+	if err != nil {
+		return 0, xerrors.Errorf("io.Copy() error: %w", err)
+	}
+	return n, nil
+}
+
+func writeComicInfo(zw *zip.Writer, info *comicInfo) error {
+
+	w, err := zw.Create(ComicInfoName)
+	if err != nil {
+		return xerrors.Errorf("Create() error: %w", err)
+	}
+
+	_, err = io.WriteString(w, xml.Header)
+	if err != nil {
+		return xerrors.Errorf("WriteString() error: %w", err)
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	err = enc.Encode(info)
+	if err != nil {
+		return xerrors.Errorf("Encode() error: %w", err)
+	}
+	return nil
+}
+
+// ExportPath is the archive next to the book, an existing file is not overwritten.
+func ExportPath(book string) string {
+
+	base := filepath.Clean(book)
+	if IsArchive(book) {
+		base = strings.TrimSuffix(base, filepath.Ext(base)) + "_export"
+	}
+
+	name := base + ".cbz"
+	for n := 2; ; n++ {
+		if _, err := os.Stat(name); os.IsNotExist(err) {
+			return name
+		}
+		name = fmt.Sprintf("%s (%d).cbz", base, n)
+	}
+}
+
+// ExportTitle is the name of the directory or the archive without the extension.
+func ExportTitle(book string) string {
+	base := filepath.Base(filepath.Clean(book))
+	if IsArchive(base) {
+		base = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	return base
+}
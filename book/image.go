@@ -1,4 +1,4 @@
-package wtv
+package book
 
 import (
 	"image"
@@ -37,6 +37,22 @@ func Load(name string) (image.Image, error) {
 	return img, nil
 }
 
+// DecodeConfig reads the size and the format of the image from the header.
+func DecodeConfig(name string) (image.Config, string, error) {
+
+	f, err := os.Open(name)
+	if err != nil {
+		return image.Config{}, "", xerrors.Errorf("os.Open() error: %w", err)
+	}
+	defer f.Close()
+
+	conf, format, err := image.DecodeConfig(f)
+	if err != nil {
+		return image.Config{}, "", xerrors.Errorf("image.DecodeConfig() error: %w", err)
+	}
+	return conf, format, nil
+}
+
 func Scale(img image.Image, scale float64) image.Image {
 	src := img.Bounds()
 	dst := image.NewRGBA(image.Rect(0, 0, int(float64(src.Dx())*scale), int(float64(src.Dy())*scale)))
@@ -44,6 +60,23 @@ func Scale(img image.Image, scale float64) image.Image {
 	return dst
 }
 
+// Thumbnail scales the image to the width and cuts off the bottom over the height.
+func Thumbnail(img image.Image, w, h int) image.Image {
+	src := img.Bounds()
+	s := float64(w) / float64(src.Dx())
+
+	dh := int(float64(src.Dy()) * s)
+	if dh > h {
+		dh = h
+	}
+	sh := int(float64(dh) / s)
+
+	sr := image.Rect(src.Min.X, src.Min.Y, src.Max.X, src.Min.Y+sh)
+	dst := image.NewRGBA(image.Rect(0, 0, w, dh))
+	draw.ApproxBiLinear.Scale(dst, dst.Bounds(), img, sr, draw.Over, nil)
+	return dst
+}
+
 func WriteImage(name string, img image.Image) error {
 	fp, err := os.Create(name)
 	if err != nil {
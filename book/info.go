@@ -0,0 +1,156 @@
+package book
+
+import (
+	"os"
+	"path/filepath"
+	"wtv/config"
+
+	"golang.org/x/xerrors"
+)
+
+// Info is what the viewer does with the book, it is printed by "wtvtool info".
+type Info struct {
+	Path    string
+	Dir     string
+	Archive bool
+	Sort    string
+
+	Pages  []PageReport
+	Orders []SortOrder
+
+	//scaled height of all pages at the width
+	Width       int
+	Length      int
+	CanOptimize bool
+	Optimize    OptimizeInfo
+	Restitch    OptimizeInfo
+}
+
+type PageReport struct {
+	File   string
+	Width  int
+	Height int
+	Format string
+	Size   int64
+	Error  string `json:",omitempty"`
+}
+
+// SortOrder is the page order of the sort type.
+type SortOrder struct {
+	Sort  string
+	Files []string
+}
+
+// OptimizeInfo is the state of the optimize or the restitch directory.
+// The viewer opens it instead of the book when it exists, the restitch directory is first.
+type OptimizeInfo struct {
+	Exists   bool
+	Dir      string `json:",omitempty"`
+	Pages    int
+	Manifest bool
+	//a source page is newer than the directory
+	Stale bool
+}
+
+// NewInfo reads the book like the viewer at the width of the screen.
+// The archive is extracted to the cache.
+func NewInfo(path string, width int) (*Info, error) {
+
+	var info Info
+	info.Path = path
+	info.Width = width
+	info.Sort = config.Get().Sort.String()
+
+	dir := path
+	if IsArchive(path) {
+		ad, err := extractArchive(path)
+		if err != nil {
+			return nil, xerrors.Errorf("extractArchive() error: %w", err)
+		}
+		dir = ad
+		info.Archive = true
+	}
+	info.Dir = dir
+
+	b, err := New(dir)
+	if err != nil {
+		return nil, xerrors.Errorf("New() error: %w", err)
+	}
+
+	for idx, name := range b.files {
+		p := PageReport{File: b.rel(name)}
+		pi, err := b.Info(idx)
+		if err != nil {
+			p.Error = CauseOf(err).Error()
+		} else {
+			p.Width, p.Height = pi.Width, pi.Height
+			p.Format = pi.Format
+			p.Size = pi.Size
+		}
+		info.Pages = append(info.Pages, p)
+	}
+
+	for t := config.NumericSortAsc; t <= config.DoNotSort; t++ {
+		sorted := b.Sorted(t)
+		order := SortOrder{Sort: t.String()}
+		for _, name := range sorted.files {
+			order.Files = append(order.Files, b.rel(name))
+		}
+		info.Orders = append(info.Orders, order)
+	}
+
+	info.Length = b.Document().Length(width)
+	info.CanOptimize = b.CanOptimize(width, 0)
+
+	o, err := optimizeInfo(b, OptimizeDirectory)
+	if err != nil {
+		return nil, xerrors.Errorf("optimizeInfo() error: %w", err)
+	}
+	info.Optimize = *o
+
+	o, err = optimizeInfo(b, RestitchDirectory)
+	if err != nil {
+		return nil, xerrors.Errorf("optimizeInfo() error: %w", err)
+	}
+	info.Restitch = *o
+	return &info, nil
+}
+
+func optimizeInfo(b *Book, name string) (*OptimizeInfo, error) {
+
+	var o OptimizeInfo
+	dir := filepath.Join(b.dir, name)
+	if _, err := os.Stat(dir); err != nil {
+		return &o, nil
+	}
+	o.Exists = true
+	o.Dir = dir
+
+	files, err := getFiles(o.Dir)
+	if err != nil {
+		return nil, xerrors.Errorf("getFiles() error: %w", err)
+	}
+	o.Pages = len(files)
+
+	if _, err := os.Stat(filepath.Join(o.Dir, OptimizeManifest)); err == nil {
+		o.Manifest = true
+	}
+
+	dinfo, err := os.Stat(o.Dir)
+	if err != nil {
+		return nil, xerrors.Errorf("os.Stat() error: %w", err)
+	}
+	for _, name := range b.files {
+		fi, err := os.Stat(name)
+		if err == nil && fi.ModTime().After(dinfo.ModTime()) {
+			o.Stale = true
+			break
+		}
+	}
+	return &o, nil
+}
+
+// rel is the path of the page in the book.
+func (b *Book) rel(name string) string {
+	return relPath(b.dir, name)
+}
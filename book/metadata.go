@@ -0,0 +1,150 @@
+package book
+
+import (
+	"encoding/gob"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+	"wtv/config"
+
+	"golang.org/x/xerrors"
+)
+
+const MetadataFileName = "metadata.gob"
+
+// PageInfo is the header information of the image file.
+type PageInfo struct {
+	Width   int
+	Height  int
+	Format  string
+	Size    int64
+	ModTime time.Time
+}
+
+// MetadataCache keeps PageInfo by the path.
+// An entry is valid while the size and the modtime of the file are not changed.
+type MetadataCache struct {
+	mutex   sync.Mutex
+	loaded  bool
+	dirty   bool
+	entries map[string]PageInfo
+
+	hits   int
+	misses int
+}
+
+var metadata = NewMetadataCache()
+
+func NewMetadataCache() *MetadataCache {
+	var c MetadataCache
+	c.entries = make(map[string]PageInfo)
+	return &c
+}
+
+func metadataPath() string {
+	return filepath.Join(config.CacheDir(), MetadataFileName)
+}
+
+// load reads the cache file once. A broken file is ignored.
+func (c *MetadataCache) load() {
+	if c.loaded {
+		return
+	}
+	c.loaded = true
+
+	fp, err := os.Open(metadataPath())
+	if err != nil {
+		return
+	}
+	defer fp.Close()
+
+	entries := make(map[string]PageInfo)
+	err = gob.NewDecoder(fp).Decode(&entries)
+	if err != nil {
+		Warn("metadata decode error", "err", err)
+		return
+	}
+	for k, v := range entries {
+		c.entries[k] = v
+	}
+}
+
+func (c *MetadataCache) Get(name string) (PageInfo, error) {
+
+	info, err := os.Stat(name)
+	if err != nil {
+		return PageInfo{}, xerrors.Errorf("os.Stat() error: %w", err)
+	}
+
+	c.mutex.Lock()
+	c.load()
+	pi, ok := c.entries[name]
+	c.mutex.Unlock()
+
+	if ok && pi.Size == info.Size() && pi.ModTime.Equal(info.ModTime()) {
+		c.mutex.Lock()
+		c.hits++
+		c.mutex.Unlock()
+		return pi, nil
+	}
+
+	conf, format, err := DecodeConfig(name)
+	if err != nil {
+		return PageInfo{}, xerrors.Errorf("DecodeConfig() error: %w", err)
+	}
+
+	pi = PageInfo{
+		Width:   conf.Width,
+		Height:  conf.Height,
+		Format:  format,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	}
+
+	c.mutex.Lock()
+	c.misses++
+	c.entries[name] = pi
+	c.dirty = true
+	c.mutex.Unlock()
+
+	return pi, nil
+}
+
+// Stats returns the hits and the misses.
+func (c *MetadataCache) Stats() (int, int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.hits, c.misses
+}
+
+// Save writes the cache file if it is changed.
+func (c *MetadataCache) Save() error {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if !c.dirty {
+		return nil
+	}
+
+	p := metadataPath()
+	err := os.MkdirAll(filepath.Dir(p), 0777)
+	if err != nil {
+		return xerrors.Errorf("os.MkdirAll() error: %w", err)
+	}
+
+	fp, err := os.Create(p)
+	if err != nil {
+		return xerrors.Errorf("os.Create() error: %w", err)
+	}
+	defer fp.Close()
+
+	err = gob.NewEncoder(fp).Encode(c.entries)
+	if err != nil {
+		return xerrors.Errorf("Encode() error: %w", err)
+	}
+
+	c.dirty = false
+	return nil
+}
@@ -0,0 +1,309 @@
+package book
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/xerrors"
+)
+
+const (
+	RestitchDirectory = ".wtv_restitch"
+	RestitchHeight    = OptimizeHeight
+	//the gutter is searched in the height * range around the cut
+	RestitchGutterRange = 0.25
+	RestitchGutterRows  = 4
+	//difference of the color in the gutter row
+	RestitchGutterTolerance = 8
+)
+
+// RestitchOptions is the option of Restitch.
+// Zero Height is RestitchHeight, zero Width is the widest page.
+type RestitchOptions struct {
+	Height int
+	Width  int
+	Gutter bool
+}
+
+// Restitch concatenates the pages in the order virtually and slices them at the height.
+// The slices are written to the restitch directory of the book and the new book is returned,
+// the viewer opens the directory instead of the book.
+// Only the rows not written yet are kept in the memory.
+func (b *Book) Restitch(opts RestitchOptions, progress func(done int)) (*Book, error) {
+
+	if opts.Height <= 0 {
+		opts.Height = RestitchHeight
+	}
+	if opts.Height > OpenGLHeight {
+		opts.Height = OpenGLHeight
+	}
+	if opts.Width <= 0 {
+		opts.Width = b.maxWidth()
+	}
+	if opts.Width <= 0 {
+		return nil, xerrors.Errorf("page size is unknown[%s]", b.dir)
+	}
+
+	dir := filepath.Join(b.root(), RestitchDirectory)
+	work := dir + ".work"
+	err := os.RemoveAll(work)
+	if err != nil {
+		return nil, xerrors.Errorf("os.RemoveAll() error: %w", err)
+	}
+	err = os.MkdirAll(work, 0777)
+	if err != nil {
+		return nil, xerrors.Errorf("os.MkdirAll() error: %w", err)
+	}
+
+	r := restitcher{opts: opts, dir: work, sources: make(map[string]string)}
+	for idx, name := range b.files {
+		if progress != nil && idx > 0 {
+			progress(idx)
+		}
+		img, err := Load(name)
+		if err != nil {
+			//the broken page is skipped, the strip continues
+			Warn("restitch page error", "file", name, "err", err)
+			b.problems.Add(name, err)
+			continue
+		}
+		err = r.add(name, img)
+		if err != nil {
+			os.RemoveAll(work)
+			return nil, xerrors.Errorf("add(%s) error: %w", name, err)
+		}
+	}
+
+	err = r.flush(true)
+	if err == nil && r.count == 0 {
+		err = xerrors.Errorf("no page is decoded")
+	}
+	if err != nil {
+		os.RemoveAll(work)
+		return nil, xerrors.Errorf("flush() error: %w", err)
+	}
+
+	//the chapters are kept by the source pages
+	err = writeManifest(work, r.sources)
+	if err != nil {
+		os.RemoveAll(work)
+		return nil, xerrors.Errorf("writeManifest() error: %w", err)
+	}
+
+	err = os.RemoveAll(dir)
+	if err != nil {
+		return nil, xerrors.Errorf("os.RemoveAll() error: %w", err)
+	}
+	err = os.Rename(work, dir)
+	if err != nil {
+		return nil, xerrors.Errorf("os.Rename() error: %w", err)
+	}
+
+	if progress != nil {
+		progress(len(b.files))
+	}
+
+	nb, err := New(dir)
+	if err != nil {
+		return nil, xerrors.Errorf("New() error: %w", err)
+	}
+	return nb, nil
+}
+
+// RemoveRestitch removes the restitch directory, the viewer opens the book again.
+func RemoveRestitch(path string) error {
+
+	dir := path
+	if IsArchive(path) {
+		ad, err := extractArchive(path)
+		if err != nil {
+			return xerrors.Errorf("extractArchive() error: %w", err)
+		}
+		dir = ad
+	}
+
+	err := os.RemoveAll(filepath.Join(dir, RestitchDirectory))
+	if err != nil {
+		return xerrors.Errorf("os.RemoveAll() error: %w", err)
+	}
+	return nil
+}
+
+// root is the directory of the source pages.
+func (b *Book) root() string {
+	switch filepath.Base(b.dir) {
+	case OptimizeDirectory, RestitchDirectory:
+		return filepath.Dir(b.dir)
+	}
+	return b.dir
+}
+
+func (b *Book) maxWidth() int {
+	max := 0
+	for idx := range b.files {
+		info, err := b.Info(idx)
+		if err == nil && info.Width > max {
+			max = info.Width
+		}
+	}
+	return max
+}
+
+func existsRestitchDirectory(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, RestitchDirectory))
+	return err == nil
+}
+
+// restitcher keeps the rows of the strip not written yet.
+type restitcher struct {
+	opts  RestitchOptions
+	dir   string
+	buf   *image.RGBA
+	count int
+
+	//pages in the buf, the slice is the source page at the top
+	pages   []restitchPage
+	sources map[string]string
+}
+
+type restitchPage struct {
+	name string
+	top  int
+}
+
+// add scales the page to the width and appends it to the strip.
+func (r *restitcher) add(name string, img image.Image) error {
+
+	src := img.Bounds()
+	w := r.opts.Width
+	h := int(math.Round(float64(src.Dy()) * float64(w) / float64(src.Dx())))
+	if h <= 0 {
+		return nil
+	}
+
+	bh := 0
+	if r.buf != nil {
+		bh = r.buf.Bounds().Dy()
+	}
+	nb := image.NewRGBA(image.Rect(0, 0, w, bh+h))
+	if r.buf != nil {
+		draw.Copy(nb, image.Point{}, r.buf, r.buf.Bounds(), draw.Src, nil)
+	}
+	if src.Dx() == w {
+		draw.Copy(nb, image.Point{0, bh}, img, src, draw.Src, nil)
+	} else {
+		draw.CatmullRom.Scale(nb, image.Rect(0, bh, w, bh+h), img, src, draw.Src, nil)
+	}
+	r.buf = nb
+	r.pages = append(r.pages, restitchPage{name, bh})
+
+	return r.flush(false)
+}
+
+// flush writes the slices of the height.
+// The rows to search the gutter are kept for the next page unless it is the last.
+func (r *restitcher) flush(last bool) error {
+
+	height := r.opts.Height
+	rng := 0
+	if r.opts.Gutter {
+		rng = int(float64(height) * RestitchGutterRange)
+	}
+
+	for r.buf != nil {
+		b := r.buf.Bounds()
+		h := b.Dy()
+
+		cut := height
+		if !last && h < height+rng {
+			return nil
+		}
+		if last && h <= height+height/2 {
+			//the short rest is not an extra slice
+			cut = h
+		} else if r.opts.Gutter {
+			cut = findGutter(r.buf, height, rng)
+		}
+
+		err := r.write(r.buf.SubImage(image.Rect(b.Min.X, b.Min.Y, b.Max.X, b.Min.Y+cut)))
+		if err != nil {
+			return xerrors.Errorf("write() error: %w", err)
+		}
+
+		if cut >= h {
+			r.buf = nil
+			r.pages = nil
+		} else {
+			r.buf = r.buf.SubImage(image.Rect(b.Min.X, b.Min.Y+cut, b.Max.X, b.Max.Y)).(*image.RGBA)
+			r.cut(cut)
+		}
+	}
+	return nil
+}
+
+// cut moves the tops of the pages, the page over the new top is kept.
+func (r *restitcher) cut(h int) {
+	var pages []restitchPage
+	for idx, p := range r.pages {
+		p.top -= h
+		if p.top <= 0 && idx+1 < len(r.pages) && r.pages[idx+1].top-h <= 0 {
+			continue
+		}
+		pages = append(pages, p)
+	}
+	r.pages = pages
+}
+
+func (r *restitcher) write(img image.Image) error {
+	r.count++
+	name := filepath.Join(r.dir, fmt.Sprintf("%05d.jpg", r.count))
+	err := WriteImage(name, img)
+	if err != nil {
+		return xerrors.Errorf("WriteImage() error: %w", err)
+	}
+	if len(r.pages) > 0 {
+		r.sources[name] = r.pages[0].name
+	}
+	return nil
+}
+
+// findGutter is the row of the blank space between the panels nearest to y.
+// Without the gutter in the range, it is y.
+func findGutter(img *image.RGBA, y, rng int) int {
+	h := img.Bounds().Dy()
+	for d := 0; d <= rng; d++ {
+		for _, cy := range []int{y - d, y + d} {
+			top := cy - RestitchGutterRows/2
+			if top <= 0 || top+RestitchGutterRows > h {
+				continue
+			}
+			if uniformRows(img, top, RestitchGutterRows) {
+				return cy
+			}
+		}
+	}
+	return y
+}
+
+// uniformRows reports whether the rows are one color.
+func uniformRows(img *image.RGBA, top, rows int) bool {
+	b := img.Bounds()
+	base := img.Pix[img.PixOffset(b.Min.X, b.Min.Y+top):]
+	for y := top; y < top+rows; y++ {
+		off := img.PixOffset(b.Min.X, b.Min.Y+y)
+		row := img.Pix[off : off+b.Dx()*4]
+		for i := 0; i < len(row); i += 4 {
+			for c := 0; c < 3; c++ {
+				d := int(row[i+c]) - int(base[c])
+				if d < -RestitchGutterTolerance || d > RestitchGutterTolerance {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
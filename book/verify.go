@@ -0,0 +1,160 @@
+package book
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/xerrors"
+)
+
+// Problem is the page that can not be read.
+type Problem struct {
+	Index int
+	File  string
+	Err   error
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("page %d %s: %v", p.Index+1, filepath.Base(p.File), CauseOf(p.Err))
+}
+
+// Problems records the errors of the pages by the file.
+// It is shared by the sorted books.
+type Problems struct {
+	mutex sync.Mutex
+	errs  map[string]error
+}
+
+func NewProblems() *Problems {
+	var p Problems
+	p.errs = make(map[string]error)
+	return &p
+}
+
+func (p *Problems) Add(name string, err error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.errs[name] = err
+}
+
+func (p *Problems) Get(name string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.errs[name]
+}
+
+func (p *Problems) Len() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return len(p.errs)
+}
+
+// CauseOf is the innermost error, the wrapped messages are too long for the screen.
+func CauseOf(err error) error {
+	for {
+		next := errors.Unwrap(err)
+		if next == nil {
+			return err
+		}
+		err = next
+	}
+}
+
+// VerifyFile decodes the whole image, a truncated file is an error.
+func VerifyFile(name string) error {
+	_, err := Load(name)
+	if err != nil {
+		return xerrors.Errorf("Load() error: %w", err)
+	}
+	return nil
+}
+
+// Verify decodes the images and the archive entries under the path.
+// fn is called for each file, err is nil when it can be read.
+// The archive entries are named "archive:entry".
+func Verify(path string, fn func(name string, err error)) error {
+
+	_, err := os.Stat(path)
+	if err != nil {
+		return xerrors.Errorf("os.Stat() error: %w", err)
+	}
+
+	err = filepath.WalkDir(path, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			fn(name, err)
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if name != path && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		if isImage(name) {
+			fn(name, VerifyFile(name))
+		} else if IsArchive(name) {
+			err := verifyArchive(name, fn)
+			if err != nil {
+				fn(name, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return xerrors.Errorf("filepath.WalkDir() error: %w", err)
+	}
+	return nil
+}
+
+func verifyArchive(name string, fn func(string, error)) error {
+
+	r, err := zip.OpenReader(name)
+	if err != nil {
+		return xerrors.Errorf("zip.OpenReader() error: %w", err)
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() || !isImage(f.Name) {
+			continue
+		}
+		fn(name+":"+f.Name, verifyEntry(f))
+	}
+	return nil
+}
+
+func verifyEntry(f *zip.File) error {
+
+	src, err := f.Open()
+	if err != nil {
+		return xerrors.Errorf("zip.File Open() error: %w", err)
+	}
+	defer src.Close()
+
+	_, _, err = image.Decode(src)
+	if err != nil {
+		return xerrors.Errorf("image.Decode() error: %w", err)
+	}
+
+	//the checksum is tested at the end of the entry
+	_, err = io.Copy(io.Discard, src)
+	if err != nil {
+		return xerrors.Errorf("io.Copy() error: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,474 @@
+package wtv
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"wtv/book"
+	"wtv/config"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font"
+	"golang.org/x/xerrors"
+)
+
+const (
+	BrowserHeaderHeight = 80
+	BrowserCellWidth    = 140
+	BrowserCellHeight   = 180
+	BrowserCellMargin   = 10
+	BrowserNameHeight   = 30
+)
+
+type browserItemKind int
+
+const (
+	browserDirectory browserItemKind = iota
+	browserArchive
+)
+
+type BrowserItem struct {
+	name  string
+	path  string
+	kind  browserItemKind
+	thumb image.Image
+
+	Shape
+	*ButtonObserver
+}
+
+func newBrowserItem(name, path string, kind browserItemKind) *BrowserItem {
+	var item BrowserItem
+	item.name = name
+	item.path = path
+	item.kind = kind
+	item.ButtonObserver = NewButton(&item)
+	item.Shape = NewRectangle(0, 0, dp(BrowserCellWidth), dp(BrowserCellHeight))
+	item.Themed(item.render)
+	return &item
+}
+
+func (item *BrowserItem) render() {
+
+	w, h := dp(BrowserCellWidth), dp(BrowserCellHeight)
+	img := ebiten.NewImage(w, h)
+	img.Fill(theme.Item)
+
+	if item.thumb != nil {
+		th := ebiten.NewImageFromImage(item.thumb)
+		op := &ebiten.DrawImageOptions{}
+		img.DrawImage(th, op)
+	} else {
+		label := "DIR"
+		if item.kind == browserArchive {
+			label = "ZIP"
+		}
+		tw := font.MeasureString(defaultFont, label).Ceil()
+		text.Draw(img, label, defaultFont, (w-tw)/2, (h-dp(BrowserNameHeight))/2, theme.Button)
+	}
+
+	ebitenutil.DrawRect(img, 0, float64(h-dp(BrowserNameHeight)), float64(w), float64(dp(BrowserNameHeight)), theme.Header)
+	text.Draw(img, fitText(item.name, w-dp(8)), defaultFont, dp(4), h-dp(8), theme.Text)
+
+	item.img = img
+}
+
+// fitText cuts the text to the width.
+func fitText(txt string, w int) string {
+	return fitFaceText(defaultFont, txt, w)
+}
+
+func fitFaceText(face font.Face, txt string, w int) string {
+	if font.MeasureString(face, txt).Ceil() <= w {
+		return txt
+	}
+	r := []rune(txt)
+	for len(r) > 0 {
+		r = r[:len(r)-1]
+		s := string(r) + "..."
+		if font.MeasureString(face, s).Ceil() <= w {
+			return s
+		}
+	}
+	return ""
+}
+
+type browserThumb struct {
+	generation int
+	index      int
+	img        image.Image
+}
+
+// Browser is a in-window book selector.
+type Browser struct {
+	scenes *SceneManager
+	dir    string
+	recent bool
+
+	message string
+
+	items  []*BrowserItem
+	header *Panel
+	events *EventDispatcher
+	scroll int
+
+	width  int
+	height int
+
+	open  func(string) error
+	cache *ThumbnailCache
+
+	mutex      sync.Mutex
+	generation int
+	thumbs     []browserThumb
+}
+
+func NewBrowser(open func(string) error) *Browser {
+
+	var b Browser
+	b.open = open
+	b.cache = NewThumbnailCache(dp(BrowserCellWidth), dp(BrowserCellHeight)-dp(BrowserNameHeight))
+	b.header = NewPanel(0, 0, 0, dp(BrowserHeaderHeight))
+	b.events = NewEventDispatcher()
+
+	upBtn := NewTextButton("Up", 0, 0, dp(90), dp(30))
+	upBtn.Click(func() error {
+		return b.up()
+	})
+	openBtn := NewTextButton("Open", 0, 0, dp(90), dp(30))
+	openBtn.Click(func() error {
+		return b.openBook(b.dir)
+	})
+	recentBtn := NewTextButton("Recent", 0, 0, dp(90), dp(30))
+	recentBtn.Click(func() error {
+		b.showRecent()
+		return nil
+	})
+	closeBtn := NewTextButton("Close", 0, 0, dp(90), dp(30))
+	closeBtn.Click(func() error {
+		return b.Close()
+	})
+
+	b.header.Add(upBtn)
+	b.header.Add(openBtn)
+	b.header.Add(recentBtn)
+
+	spacer := NewSpacer(0, 0)
+	b.header.Add(spacer)
+	b.header.Add(closeBtn)
+
+	l := NewHBox()
+	l.Padding = NewPadding(dp(10))
+	l.Spacing = dp(10)
+	l.Align = AlignStart
+	l.Grow(spacer, 1)
+	b.header.SetLayout(l)
+
+	return &b
+}
+
+// StartDirectory is the parent of the last book or the home directory.
+func StartDirectory() string {
+	conf := config.Get()
+	if conf.Directory != "" {
+		dir := filepath.Dir(conf.Directory)
+		if _, err := os.Stat(dir); err == nil {
+			return dir
+		}
+	}
+	return config.HomeDir()
+}
+
+// Show reads the directory before the browser is pushed.
+func (b *Browser) Show(dir string) {
+	b.message = ""
+	err := b.chdir(dir)
+	if err != nil {
+		b.message = err.Error()
+		logger.Warn("browser chdir error", F("dir", dir), F("err", err))
+	}
+}
+
+func (b *Browser) Enter(m *SceneManager) error {
+	b.scenes = m
+	return nil
+}
+
+func (b *Browser) Leave() error {
+	b.setItems(nil)
+	return nil
+}
+
+func (b *Browser) Close() error {
+	if b.scenes == nil {
+		return nil
+	}
+	return b.scenes.Remove(b)
+}
+
+func (b *Browser) chdir(dir string) error {
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return xerrors.Errorf("os.ReadDir() error: %w", err)
+	}
+
+	var dirs []*BrowserItem
+	var archives []*BrowserItem
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		if entry.IsDir() {
+			dirs = append(dirs, newBrowserItem(name, path, browserDirectory))
+		} else if book.IsArchive(name) {
+			archives = append(archives, newBrowserItem(name, path, browserArchive))
+		}
+	}
+
+	sort.Slice(dirs, func(i, j int) bool {
+		return dirs[i].name < dirs[j].name
+	})
+	sort.Slice(archives, func(i, j int) bool {
+		return archives[i].name < archives[j].name
+	})
+
+	b.dir = dir
+	b.recent = false
+	b.message = ""
+	b.setItems(append(dirs, archives...))
+	return nil
+}
+
+func (b *Browser) showRecent() {
+
+	var items []*BrowserItem
+	for _, path := range config.Get().Recent {
+		kind := browserDirectory
+		if book.IsArchive(path) {
+			kind = browserArchive
+		}
+		items = append(items, newBrowserItem(filepath.Base(path), path, kind))
+	}
+
+	b.recent = true
+	b.message = ""
+	b.setItems(items)
+}
+
+func (b *Browser) setItems(items []*BrowserItem) {
+
+	b.mutex.Lock()
+	b.generation++
+	gen := b.generation
+	b.thumbs = nil
+	b.mutex.Unlock()
+
+	b.items = items
+	b.scroll = 0
+
+	var paths []string
+	for _, item := range items {
+		item := item
+		item.Click(func() error {
+			return b.selectItem(item)
+		})
+		paths = append(paths, item.path)
+	}
+
+	if len(paths) > 0 {
+		go b.loadThumbnails(gen, paths)
+	}
+}
+
+func (b *Browser) loadThumbnails(gen int, paths []string) {
+	for idx, path := range paths {
+
+		b.mutex.Lock()
+		stale := gen != b.generation
+		b.mutex.Unlock()
+		if stale {
+			return
+		}
+
+		th, err := b.cache.Get(path, book.LoadCover)
+		if err != nil {
+			continue
+		}
+
+		b.mutex.Lock()
+		if gen == b.generation {
+			b.thumbs = append(b.thumbs, browserThumb{gen, idx, th})
+		}
+		b.mutex.Unlock()
+	}
+}
+
+func (b *Browser) up() error {
+	if b.recent {
+		return b.chdir(b.dir)
+	}
+	parent := filepath.Dir(b.dir)
+	if parent == b.dir {
+		return nil
+	}
+	err := b.chdir(parent)
+	if err != nil {
+		b.message = err.Error()
+	}
+	return nil
+}
+
+func (b *Browser) selectItem(item *BrowserItem) error {
+	if item.kind == browserDirectory && !b.recent {
+		err := b.chdir(item.path)
+		if err != nil {
+			b.message = err.Error()
+		}
+		return nil
+	}
+	return b.openBook(item.path)
+}
+
+func (b *Browser) openBook(path string) error {
+	err := b.open(path)
+	if err != nil {
+		b.message = err.Error()
+		logger.Error("book open error", F("path", path), F("err", err))
+		return nil
+	}
+	return b.Close()
+}
+
+func (b *Browser) columns() int {
+	col := (b.width - dp(BrowserCellMargin)) / (dp(BrowserCellWidth) + dp(BrowserCellMargin))
+	if col < 1 {
+		col = 1
+	}
+	return col
+}
+
+func (b *Browser) layout() {
+
+	col := b.columns()
+	rows := (len(b.items) + col - 1) / col
+
+	max := rows*(dp(BrowserCellHeight)+dp(BrowserCellMargin)) + dp(BrowserCellMargin) - (b.height - dp(BrowserHeaderHeight))
+	if b.scroll > max {
+		b.scroll = max
+	}
+	if b.scroll < 0 {
+		b.scroll = 0
+	}
+
+	for idx, item := range b.items {
+		x := dp(BrowserCellMargin) + (idx%col)*(dp(BrowserCellWidth)+dp(BrowserCellMargin))
+		y := dp(BrowserHeaderHeight) + dp(BrowserCellMargin) + (idx/col)*(dp(BrowserCellHeight)+dp(BrowserCellMargin)) - b.scroll
+		item.Move(x, y)
+	}
+}
+
+func (b *Browser) Update(w, h int) error {
+
+	if b.width != w {
+		b.header.Set(w, dp(BrowserHeaderHeight))
+	}
+	b.width, b.height = w, h
+
+	b.mutex.Lock()
+	thumbs := b.thumbs
+	b.thumbs = nil
+	b.mutex.Unlock()
+	for _, th := range thumbs {
+		if th.index < len(b.items) {
+			item := b.items[th.index]
+			item.thumb = th.img
+			item.render()
+		}
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		return b.Close()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyBackspace) {
+		return b.up()
+	}
+
+	_, dy := ebiten.Wheel()
+	b.scroll -= int(dy * float64(dp(40)))
+	b.layout()
+
+	x, y := ebiten.CursorPosition()
+
+	err := b.header.Update(x, y)
+	if err != nil {
+		return xerrors.Errorf("header Update() error: %w", err)
+	}
+
+	err = b.events.Dispatch(b.components(), x, y)
+	if err != nil {
+		return xerrors.Errorf("Dispatch() error: %w", err)
+	}
+	return nil
+}
+
+func (b *Browser) Draw(screen *ebiten.Image) error {
+
+	screen.Fill(theme.Background)
+
+	for idx, item := range b.items {
+		_, y := item.Point()
+		if int(y)+dp(BrowserCellHeight) < dp(BrowserHeaderHeight) || int(y) > b.height {
+			continue
+		}
+		err := item.Draw(screen)
+		if err != nil {
+			return xerrors.Errorf("item[%d] Draw() error: %w", idx, err)
+		}
+	}
+
+	ebitenutil.DrawRect(screen, 0, 0, float64(b.width), float64(dp(BrowserHeaderHeight)), theme.Header)
+	err := b.header.Draw(screen)
+	if err != nil {
+		return xerrors.Errorf("header Draw() error: %w", err)
+	}
+
+	label := b.dir
+	if b.recent {
+		label = "Recent"
+	}
+	clr := color.Color(theme.Text)
+	if b.message != "" {
+		label = b.message
+		clr = theme.Error
+	}
+	text.Draw(screen, fitText(label, b.width-dp(20)), defaultFont, dp(10), dp(BrowserHeaderHeight)-dp(12), clr)
+
+	return nil
+}
+
+// components are the items and the header, the header is over the items.
+func (b *Browser) components() []Component {
+	list := make([]Component, 0, len(b.items)+1)
+	for _, item := range b.items {
+		list = append(list, item)
+	}
+	return append(list, b.header)
+}
+
+func (b *Browser) Inspect() ([]Component, []string) {
+	return b.components(), []string{
+		fmt.Sprintf("items %d scroll %d", len(b.items), b.scroll),
+		fmt.Sprintf("hover %T", b.events.Hover()),
+	}
+}
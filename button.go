@@ -1,12 +1,9 @@
 package wtv
 
 import (
-	"image/color"
-	"log"
-
 	"github.com/fogleman/gg"
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/text"
 	"golang.org/x/image/font"
 	"golang.org/x/xerrors"
@@ -16,12 +13,20 @@ type Button interface {
 	Component
 }
 
-var buttonColor = color.RGBA{128, 128, 128, 255}
-
+// ButtonObserver is the state of the button.
+// hover and pressed are by the mouse, selected is the focus by the gamepad.
 type ButtonObserver struct {
-	img   *ebiten.Image
-	focus bool
-	click func() error
+	img      *ebiten.Image
+	hover    bool
+	pressed  bool
+	selected bool
+	active   bool
+	disabled bool
+	click    func() error
+
+	//render draws the image again when the theme is changed
+	render func()
+	themed int
 
 	Button
 }
@@ -32,32 +37,108 @@ func NewButton(b Button) *ButtonObserver {
 	return &bo
 }
 
+// Observer returns the state of the embedded button.
+func (bo *ButtonObserver) Observer() *ButtonObserver {
+	return bo
+}
+
+// Themed draws the button image by fn, now and after the theme is changed.
+func (bo *ButtonObserver) Themed(fn func()) {
+	bo.render = fn
+	bo.themed = themeGeneration
+	fn()
+}
+
 func (bo *ButtonObserver) Click(fn func() error) {
 	bo.click = fn
 }
 
+// Toggle makes the button switch the active state by the click.
+func (bo *ButtonObserver) Toggle(fn func(bool) error) {
+	bo.click = func() error {
+		bo.active = !bo.active
+		return fn(bo.active)
+	}
+}
+
+func (bo *ButtonObserver) SetActive(a bool) {
+	bo.active = a
+}
+
+func (bo *ButtonObserver) IsActive() bool {
+	return bo.active
+}
+
+func (bo *ButtonObserver) SetDisabled(d bool) {
+	bo.disabled = d
+	if d {
+		bo.pressed = false
+	}
+}
+
+func (bo *ButtonObserver) Disabled() bool {
+	return bo.disabled
+}
+
+// Update is nothing, the button is moved by the events.
 func (bo *ButtonObserver) Update(x, y int) error {
+	return nil
+}
+
+func (bo *ButtonObserver) HandleEvent(e *Event) error {
 
 	if bo == nil {
 		return nil
 	}
-	bo.focus = false
-
-	if bo.Button.In(x, y) {
-		bo.focus = true
-		if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
-			err := bo.click()
-			if err != nil {
-				return xerrors.Errorf("click() error: %w", err)
-			}
+
+	switch e.Type {
+	case MouseEnterEvent:
+		bo.hover = true
+	case MouseLeaveEvent:
+		bo.hover = false
+	case MouseDownEvent:
+		bo.pressed = !bo.disabled
+	case MouseUpEvent:
+		bo.pressed = false
+	case ClickEvent:
+		e.Consume()
+		err := bo.Activate()
+		if err != nil {
+			return xerrors.Errorf("Activate() error: %w", err)
 		}
 	}
 	return nil
 }
 
+// Set is nothing, the button image is the fixed size.
+func (bo *ButtonObserver) Set(w, h int) {
+}
+
+func (bo *ButtonObserver) SetFocus(f bool) {
+	bo.selected = f
+}
+
+func (bo *ButtonObserver) Activate() error {
+	if bo.click == nil || bo.disabled {
+		return nil
+	}
+	err := bo.click()
+	if err != nil {
+		return xerrors.Errorf("click() error: %w", err)
+	}
+	return nil
+}
+
 func (bo *ButtonObserver) Draw(img *ebiten.Image) error {
 
-	if bo == nil || bo.img == nil {
+	if bo == nil {
+		return nil
+	}
+	if bo.render != nil && bo.themed != themeGeneration {
+		bo.themed = themeGeneration
+		bo.render()
+	}
+	if bo.img == nil {
 		return nil
 	}
 
@@ -65,14 +146,70 @@ func (bo *ButtonObserver) Draw(img *ebiten.Image) error {
 
 	cop.GeoM.Translate(bo.Point())
 
-	if bo.focus {
+	switch {
+	case bo.disabled:
+		cop.ColorM.Scale(1, 1, 1, 0.3)
+	case bo.pressed && bo.hover:
+		cop.ColorM.Scale(0.7, 0.7, 0.7, 1)
+	case bo.hover || bo.selected:
 		cop.ColorM.Scale(1, 1, 1, 0.8)
 	}
 	img.DrawImage(bo.img, cop)
 
+	if bo.active {
+		x, y := bo.Point()
+		w, h := bo.Size()
+		ebitenutil.DrawRect(img, x, y+float64(h)-dpf(3), float64(w), dpf(3), theme.Active)
+	}
+
 	return nil
 }
 
+// Observed is the button that has the ButtonObserver.
+type Observed interface {
+	Observer() *ButtonObserver
+}
+
+// RadioGroup keeps only one button active.
+type RadioGroup struct {
+	buttons []*ButtonObserver
+	values  []int
+	change  func(int) error
+}
+
+func NewRadioGroup(change func(int) error) *RadioGroup {
+	var g RadioGroup
+	g.change = change
+	return &g
+}
+
+func (g *RadioGroup) Add(b Observed, v int) {
+	bo := b.Observer()
+	g.buttons = append(g.buttons, bo)
+	g.values = append(g.values, v)
+	bo.Click(func() error {
+		if bo.active {
+			return nil
+		}
+		g.Set(v)
+		return g.change(v)
+	})
+}
+
+// Set changes the active button without the change function.
+func (g *RadioGroup) Set(v int) {
+	for idx, bo := range g.buttons {
+		bo.active = g.values[idx] == v
+	}
+}
+
+// Clear makes no button active.
+func (g *RadioGroup) Clear() {
+	for _, bo := range g.buttons {
+		bo.active = false
+	}
+}
+
 type RectButton struct {
 	Shape
 	*ButtonObserver
@@ -86,32 +223,45 @@ func NewRectButton(x, y int) *RectButton {
 
 type TextButton struct {
 	*RectButton
+	text string
 }
 
 func NewTextButton(txt string, x, y, w, h int) *TextButton {
 	var t TextButton
 
 	t.RectButton = NewRectButton(x, y)
-	btn := ebiten.NewImage(w, h)
-	btn.Fill(buttonColor)
+	t.Shape = NewRectangle(x, y, w, h)
+	t.text = txt
+	t.Themed(t.setBaseImage)
+
+	return &t
+}
+
+func (t *TextButton) setBaseImage() {
+
+	w, h := t.Size()
+
+	dc := gg.NewContext(w, h)
+	dc.DrawRoundedRectangle(0, 0, float64(w), float64(h), float64(theme.CornerRadius))
+	dc.SetColor(theme.Button)
+	dc.Fill()
+	btn := ebiten.NewImageFromImage(dc.Image())
 
-	tw := font.MeasureString(defaultFont, txt).Ceil()
+	tw := font.MeasureString(defaultFont, t.text).Ceil()
 	th := defaultFont.Metrics().XHeight.Ceil()
 
 	dx := (w / 2) - (tw / 2)
 	dy := (h / 2) + (th / 2) + 2
 
-	t.Shape = NewRectangle(x, y, w, h)
-	text.Draw(btn, txt, defaultFont, dx, dy, color.Black)
+	text.Draw(btn, t.text, defaultFont, dx, dy, theme.ButtonText)
 
 	t.img = btn
-
-	return &t
 }
 
 type CircleButton struct {
 	*ButtonObserver
 	Shape
+	icon ResourceName
 }
 
 func NewCircleButton(x, y, r int) *CircleButton {
@@ -120,7 +270,7 @@ func NewCircleButton(x, y, r int) *CircleButton {
 	c.ButtonObserver = NewButton(&c)
 	c.Shape = NewCircle(x, y, r)
 
-	c.setBaseImage()
+	c.Themed(c.setBaseImage)
 
 	return &c
 }
@@ -129,27 +279,31 @@ func (c *CircleButton) setBaseImage() {
 
 	cs, ok := c.Shape.(*Circle)
 	if !ok {
-		log.Println("Shape Cast(Circle) error")
+		logger.Error("Shape Cast(Circle) error")
 	}
 
 	r := cs.r
-	rc, gc, bc, _ := buttonColor.RGBA()
 
 	dc := gg.NewContext(r*2, r*2)
 	dc.DrawCircle(float64(r), float64(r), float64(r))
-	dc.SetRGB(float64(rc), float64(gc), float64(bc))
+	dc.SetColor(theme.Button)
 	dc.Fill()
 
 	img := ebiten.NewImageFromImage(dc.Image())
 	c.img = img
+
+	if c.icon != "" {
+		res := ebiten.NewImageFromImage(GetImage(c.icon))
+		op := &ebiten.DrawImageOptions{}
+		//the icon is for the scale 1
+		op.GeoM.Scale(uiScale, uiScale)
+		op.GeoM.Translate(dpf(8), dpf(8))
+		op.Filter = ebiten.FilterLinear
+		c.img.DrawImage(res, op)
+	}
 }
 
 func (c *CircleButton) PasteImage(name ResourceName) {
-
+	c.icon = name
 	c.setBaseImage()
-
-	res := ebiten.NewImageFromImage(GetImage(name))
-	op := &ebiten.DrawImageOptions{}
-	op.GeoM.Translate(float64(8), float64(8))
-	c.img.DrawImage(res, op)
 }
@@ -0,0 +1,105 @@
+package wtv
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font"
+	"golang.org/x/xerrors"
+)
+
+const (
+	CheckboxSize    = 20
+	CheckboxSpacing = 8
+)
+
+type Checkbox struct {
+	*Rectangle
+
+	label    string
+	checked  bool
+	hover    bool
+	selected bool
+
+	changeFunc func(bool) error
+}
+
+func NewCheckbox(label string, x, y int) *Checkbox {
+	var c Checkbox
+	c.label = label
+	w := dp(CheckboxSize) + dp(CheckboxSpacing) + font.MeasureString(defaultFont, label).Ceil()
+	c.Rectangle = NewRectangle(x, y, w, dp(CheckboxSize))
+	return &c
+}
+
+func (c *Checkbox) Changed(fn func(bool) error) {
+	c.changeFunc = fn
+}
+
+func (c *Checkbox) SetChecked(v bool) {
+	c.checked = v
+}
+
+func (c *Checkbox) Checked() bool {
+	return c.checked
+}
+
+// Set is nothing, the size is decided by the label.
+func (c *Checkbox) Set(w, h int) {
+}
+
+func (c *Checkbox) SetFocus(f bool) {
+	c.selected = f
+}
+
+func (c *Checkbox) Activate() error {
+	c.checked = !c.checked
+	if c.changeFunc == nil {
+		return nil
+	}
+	err := c.changeFunc(c.checked)
+	if err != nil {
+		return xerrors.Errorf("changeFunc() error: %w", err)
+	}
+	return nil
+}
+
+func (c *Checkbox) HandleEvent(e *Event) error {
+	switch e.Type {
+	case MouseEnterEvent:
+		c.hover = true
+	case MouseLeaveEvent:
+		c.hover = false
+	case ClickEvent:
+		e.Consume()
+		return c.Activate()
+	}
+	return nil
+}
+
+func (c *Checkbox) Update(x, y int) error {
+	return nil
+}
+
+func (c *Checkbox) Draw(img *ebiten.Image) error {
+
+	x, y := float64(c.x), float64(c.y)
+	s := float64(dp(CheckboxSize))
+
+	border := color.Color(theme.Button)
+	if c.hover || c.selected {
+		border = theme.Text
+	}
+	ebitenutil.DrawRect(img, x, y, s, s, border)
+	b, m := dpf(2), dpf(5)
+	ebitenutil.DrawRect(img, x+b, y+b, s-b*2, s-b*2, theme.Surface)
+	if c.checked {
+		ebitenutil.DrawRect(img, x+m, y+m, s-m*2, s-m*2, theme.Active)
+	}
+
+	th := defaultFont.Metrics().XHeight.Ceil()
+	text.Draw(img, c.label, defaultFont, c.x+dp(CheckboxSize)+dp(CheckboxSpacing), c.y+(dp(CheckboxSize)+th)/2, theme.Text)
+	return nil
+}
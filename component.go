@@ -22,21 +22,55 @@ func NewComponent(c Component) *ComponentObserver {
 	return &o
 }
 
+// Focusable is a component that can be selected without the mouse.
+type Focusable interface {
+	SetFocus(bool)
+}
+
+// Activator is a component that can be pushed without the mouse.
+type Activator interface {
+	Activate() error
+}
+
 type Components struct {
 	parent   Component
 	children []Component
+	focus    int
+	layout   LayoutManager
 }
 
-func NewComponents() *Components {
+// NewComponents is the children of the parent, parent is nil at the root.
+func NewComponents(parent Component) *Components {
 	var c Components
-	//TODO parent
+	c.parent = parent
+	c.focus = -1
 	return &c
 }
 
+func (c *Components) Parent() Component {
+	return c.parent
+}
+
 func (c *Components) Add(comp Component) {
 	c.children = append(c.children, comp)
 }
 
+func (c *Components) Children() []Component {
+	return c.children
+}
+
+func (c *Components) SetLayout(l LayoutManager) {
+	c.layout = l
+}
+
+// Set arranges the children in the size.
+func (c *Components) Set(w, h int) {
+	if c.layout == nil {
+		return
+	}
+	c.layout.Arrange(c.children, 0, 0, w, h)
+}
+
 func (c *Components) Update(x, y int) error {
 	for idx, comp := range c.children {
 		err := comp.Update(x, y)
@@ -47,12 +81,125 @@ func (c *Components) Update(x, y int) error {
 	return nil
 }
 
+// Draw draws the overlay children at last.
 func (c *Components) Draw(img *ebiten.Image) error {
-	for idx, comp := range c.children {
-		err := comp.Draw(img)
-		if err != nil {
-			return xerrors.Errorf("Components[%d Draw() error: %w", idx, err)
+	for _, over := range []bool{false, true} {
+		for idx, comp := range c.children {
+			if isOverlay(comp) != over {
+				continue
+			}
+			err := comp.Draw(img)
+			if err != nil {
+				return xerrors.Errorf("Components[%d Draw() error: %w", idx, err)
+			}
 		}
 	}
 	return nil
 }
+
+func (c *Components) FocusNext() {
+	c.moveFocus(1)
+}
+
+func (c *Components) FocusPrev() {
+	c.moveFocus(-1)
+}
+
+func (c *Components) moveFocus(d int) {
+
+	leng := len(c.children)
+	if leng == 0 {
+		return
+	}
+
+	idx := c.focus
+	if idx == -1 && d < 0 {
+		idx = leng
+	}
+
+	for i := 0; i < leng; i++ {
+		idx = (idx + d + leng) % leng
+		if _, ok := c.children[idx].(Focusable); ok {
+			c.setFocus(idx)
+			return
+		}
+	}
+}
+
+func (c *Components) setFocus(idx int) {
+	if c.focus != -1 {
+		if f, ok := c.children[c.focus].(Focusable); ok {
+			f.SetFocus(false)
+		}
+	}
+	c.focus = idx
+	if idx != -1 {
+		if f, ok := c.children[idx].(Focusable); ok {
+			f.SetFocus(true)
+		}
+	}
+}
+
+func (c *Components) Blur() {
+	c.setFocus(-1)
+}
+
+func (c *Components) Activate() error {
+	if c.focus == -1 {
+		return nil
+	}
+	a, ok := c.children[c.focus].(Activator)
+	if !ok {
+		return nil
+	}
+	err := a.Activate()
+	if err != nil {
+		return xerrors.Errorf("Components[%d] Activate() error: %w", c.focus, err)
+	}
+	return nil
+}
+
+// Panel is a Component that draws the children in its own coordinates.
+type Panel struct {
+	*Rectangle
+	img *ebiten.Image
+	*Components
+}
+
+func NewPanel(x, y, w, h int) *Panel {
+	var p Panel
+	p.Rectangle = NewRectangle(x, y, w, h)
+	p.Components = NewComponents(&p)
+	return &p
+}
+
+func (p *Panel) Set(w, h int) {
+	p.w = w
+	p.h = h
+	p.Components.Set(w, h)
+}
+
+func (p *Panel) Update(x, y int) error {
+	return p.Components.Update(x-p.x, y-p.y)
+}
+
+func (p *Panel) Draw(img *ebiten.Image) error {
+
+	if p.w <= 0 || p.h <= 0 {
+		return nil
+	}
+	if p.img == nil || p.img.Bounds().Dx() != p.w || p.img.Bounds().Dy() != p.h {
+		p.img = ebiten.NewImage(p.w, p.h)
+	}
+	p.img.Clear()
+
+	err := p.Components.Draw(p.img)
+	if err != nil {
+		return xerrors.Errorf("Components.Draw() error: %w", err)
+	}
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(p.Point())
+	img.DrawImage(p.img, op)
+	return nil
+}
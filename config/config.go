@@ -3,6 +3,7 @@ package config
 import (
 	"bytes"
 	"encoding/gob"
+	"image/color"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -13,6 +14,9 @@ import (
 var gConf *Config
 
 type Config struct {
+	//configVersion of the saved file, zero is the file before the version
+	Version int
+
 	Directory string
 	Direction Direction
 	Effect    Effect
@@ -20,10 +24,33 @@ type Config struct {
 	Width     int
 	Height    int
 	Sort      SortType
+	Gamepad   GamepadMapping
+	Recent    []string
+	//reading position(offset / width) of the recent books
+	Positions map[string]float64
+
+	//pixels per frame of the auto play
+	AutoScrollSpeed int
+	Background      color.RGBA
+	//pages loaded before and after the screen
+	Prefetch int
+	//empty is the default(~/.wtv_cache)
+	CacheDirectory string
+	//"dark", "light" or the theme file
+	Theme string
+	//font files used before the embedded font
+	Fonts []string
+	//ComicInfo.xml is written in the exported archive
+	ExportComicInfo bool
 }
 
 const (
 	defaultConfigFileName = ".wtv_config_gob"
+	defaultCacheDirName   = ".wtv_cache"
+	defaultStateDirName   = ".wtv_state"
+	maxRecent             = 10
+	//the fields added after the version are set to the default in upgrade()
+	configVersion = 1
 )
 
 func init() {
@@ -33,6 +60,7 @@ func init() {
 
 func defaultConfig() *Config {
 	var cnf Config
+	cnf.Version = configVersion
 	cnf.Directory = ""
 	cnf.Direction = Down
 	cnf.Effect = Scroll
@@ -40,6 +68,13 @@ func defaultConfig() *Config {
 	cnf.FitMode = true
 	cnf.Width = 500
 	cnf.Height = 800
+	cnf.Gamepad = defaultGamepadMapping()
+	cnf.AutoScrollSpeed = 5
+	cnf.Background = color.RGBA{0, 0, 0, 255}
+	cnf.Prefetch = 1
+	cnf.CacheDirectory = ""
+	cnf.Theme = "dark"
+	cnf.ExportComicInfo = true
 	return &cnf
 }
 
@@ -47,6 +82,38 @@ func Get() *Config {
 	return gConf
 }
 
+// AddRecent puts the book path at the head of the recent books.
+func (c *Config) AddRecent(p string) {
+	rtn := []string{p}
+	for _, elm := range c.Recent {
+		if elm == p {
+			continue
+		}
+		if len(rtn) >= maxRecent {
+			break
+		}
+		rtn = append(rtn, elm)
+	}
+	c.Recent = rtn
+}
+
+func (c *Config) Position(p string) (float64, bool) {
+	v, ok := c.Positions[p]
+	return v, ok
+}
+
+// SetPosition records the position, the books not in the recent are forgotten.
+func (c *Config) SetPosition(p string, v float64) {
+	rtn := make(map[string]float64)
+	for _, elm := range c.Recent {
+		if pos, ok := c.Positions[elm]; ok {
+			rtn[elm] = pos
+		}
+	}
+	rtn[p] = v
+	c.Positions = rtn
+}
+
 type Direction int
 
 const (
@@ -82,23 +149,39 @@ func (c *Config) load(name string) error {
 
 	fp, err := os.Open(name)
 	if err != nil {
-		return xerrors.Errorf("os.Create() error: %w")
+		return xerrors.Errorf("os.Open() error: %w", err)
 	}
 	defer fp.Close()
 
 	dec := gob.NewDecoder(fp)
 
+	//gob does not write the zero values, the saved false and 0 are decoded by the zero Config
 	var cnf Config
 	err = dec.Decode(&cnf)
 	if err != nil {
-		return xerrors.Errorf("Decode() error: %w")
+		return xerrors.Errorf("Decode() error: %w", err)
 	}
+	cnf.upgrade()
 
 	gConf = &cnf
 
 	return nil
 }
 
+// upgrade sets the default to the fields missing in the file of the old version.
+func (c *Config) upgrade() {
+	def := defaultConfig()
+	if c.Version < 1 {
+		c.Gamepad = def.Gamepad
+		c.AutoScrollSpeed = def.AutoScrollSpeed
+		c.Background = def.Background
+		c.Prefetch = def.Prefetch
+		c.Theme = def.Theme
+		c.ExportComicInfo = def.ExportComicInfo
+	}
+	c.Version = configVersion
+}
+
 func Save() error {
 
 	p := getPath()
@@ -116,18 +199,18 @@ func (c *Config) save(name string) error {
 
 	err := enc.Encode(c)
 	if err != nil {
-		return xerrors.Errorf("Encode() error: %w")
+		return xerrors.Errorf("Encode() error: %w", err)
 	}
 
 	fp, err := os.Create(name)
 	if err != nil {
-		return xerrors.Errorf("os.Create() error: %w")
+		return xerrors.Errorf("os.Create() error: %w", err)
 	}
 	defer fp.Close()
 
 	_, err = fp.Write(buf.Bytes())
 	if err != nil {
-		return xerrors.Errorf("Write() error: %w")
+		return xerrors.Errorf("Write() error: %w", err)
 	}
 
 	return nil
@@ -138,6 +221,24 @@ func getPath() string {
 	return filepath.Join(path, defaultConfigFileName)
 }
 
+// CacheDir is the directory for generated files(archive, thumbnail...)
+func CacheDir() string {
+	if gConf.CacheDirectory != "" {
+		return gConf.CacheDirectory
+	}
+	return filepath.Join(getHome(), defaultCacheDirName)
+}
+
+// StateDir is the directory for the files written while running(log...)
+func StateDir() string {
+	return filepath.Join(getHome(), defaultStateDirName)
+}
+
+// HomeDir is the user home directory.
+func HomeDir() string {
+	return getHome()
+}
+
 func getHome() string {
 	env := "HOME"
 	if runtime.GOOS == "windows" {
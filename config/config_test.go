@@ -0,0 +1,83 @@
+package config
+
+import (
+	"encoding/gob"
+	"image/color"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestConfigRoundTrip(t *testing.T) {
+
+	defer func(c *Config) { gConf = c }(gConf)
+
+	//zero values are not written by gob
+	cnf := defaultConfig()
+	cnf.FitMode = false
+	cnf.ExportComicInfo = false
+	cnf.Direction = Up
+	cnf.Sort = NumericSort
+	cnf.Gamepad.PrevPage = 0
+	cnf.Gamepad.Deadzone = 0
+	cnf.Background = color.RGBA{10, 20, 30, 0}
+	cnf.Prefetch = 0
+	cnf.Recent = []string{"a", "b"}
+	cnf.Positions = map[string]float64{"a": 1.5}
+
+	name := filepath.Join(t.TempDir(), defaultConfigFileName)
+	err := cnf.save(name)
+	if err != nil {
+		t.Fatalf("save() error: %v", err)
+	}
+
+	err = defaultConfig().load(name)
+	if err != nil {
+		t.Fatalf("load() error: %v", err)
+	}
+	if !reflect.DeepEqual(Get(), cnf) {
+		t.Errorf("want %+v\ngot  %+v", cnf, Get())
+	}
+}
+
+func TestConfigUpgrade(t *testing.T) {
+
+	defer func(c *Config) { gConf = c }(gConf)
+
+	//the file before the version
+	type oldConfig struct {
+		Directory string
+		FitMode   bool
+		Width     int
+		Height    int
+	}
+
+	name := filepath.Join(t.TempDir(), defaultConfigFileName)
+	fp, err := os.Create(name)
+	if err != nil {
+		t.Fatalf("os.Create() error: %v", err)
+	}
+	err = gob.NewEncoder(fp).Encode(oldConfig{Directory: "books", Width: 600, Height: 900})
+	fp.Close()
+	if err != nil {
+		t.Fatalf("Encode() error: %v", err)
+	}
+
+	err = defaultConfig().load(name)
+	if err != nil {
+		t.Fatalf("load() error: %v", err)
+	}
+
+	want := defaultConfig()
+	want.Directory = "books"
+	want.FitMode = false
+	want.Width = 600
+	want.Height = 900
+	want.Direction = Up
+	want.Effect = Fadein
+	want.Sort = NumericSortAsc
+	if !reflect.DeepEqual(Get(), want) {
+		t.Errorf("want %+v\ngot  %+v", want, Get())
+	}
+}
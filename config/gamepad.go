@@ -0,0 +1,36 @@
+package config
+
+// GamepadMapping is button and axis numbers of ebiten.GamepadButton / GamepadAxis.
+// Default is a XInput(Xbox) layout of the GLFW button numbers.
+type GamepadMapping struct {
+	ScrollAxis  int
+	Deadzone    float64
+	ScrollSpeed int
+
+	PrevPage    int
+	NextPage    int
+	PrevChapter int
+	NextChapter int
+	Menu        int
+	FocusPrev   int
+	FocusNext   int
+	Activate    int
+}
+
+func defaultGamepadMapping() GamepadMapping {
+	var m GamepadMapping
+	m.ScrollAxis = 1
+	m.Deadzone = 0.2
+	m.ScrollSpeed = 30
+
+	//D-pad is 10 up, 11 right, 12 down, 13 left
+	m.PrevPage = 10
+	m.NextPage = 12
+	m.PrevChapter = 4
+	m.NextChapter = 5
+	m.Menu = 7
+	m.FocusPrev = 13
+	m.FocusNext = 11
+	m.Activate = 0
+	return m
+}
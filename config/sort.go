@@ -18,14 +18,34 @@ const (
 	AlphamericSortDesc
 	ModTimeSortAsc
 	ModTimeSortDesc
+	DoNotSort
+)
 
+const (
 	NumericSort    = NumericSortAsc
 	AlphamericSort = AlphamericSortAsc
 	ModTimeSort    = ModTimeSortAsc
-
-	DoNotSort
 )
 
+var sortTypeNames = []string{"numeric", "numeric-desc", "alphameric", "alphameric-desc", "modtime", "modtime-desc", "none"}
+
+func (t SortType) String() string {
+	if t < 0 || int(t) >= len(sortTypeNames) {
+		return "unknown"
+	}
+	return sortTypeNames[t]
+}
+
+// ParseSortType is the sort type of the name(numeric, numeric-desc...).
+func ParseSortType(name string) (SortType, error) {
+	for idx, elm := range sortTypeNames {
+		if strings.EqualFold(name, elm) {
+			return SortType(idx), nil
+		}
+	}
+	return DoNotSort, xerrors.Errorf("unknown sort[%s]", name)
+}
+
 func (t SortType) Order(v bool) bool {
 	if t.Asc() {
 		return v
@@ -41,6 +61,14 @@ func (t SortType) Asc() bool {
 	return false
 }
 
+// Kind returns the ascending type of the same sort.
+func (t SortType) Kind() SortType {
+	if t.Asc() || t == DoNotSort {
+		return t
+	}
+	return t.Reverse()
+}
+
 func (t SortType) IsNumeric() bool {
 	if t == NumericSortAsc || t == NumericSortDesc {
 		return true
@@ -0,0 +1,140 @@
+package wtv
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+const (
+	DebugKey           = ebiten.KeyF3
+	DebugDecodeSamples = 30
+	DebugPanelWidth    = 320
+)
+
+// Inspector gives the debug overlay the components and the state of the scene.
+type Inspector interface {
+	Inspect() ([]Component, []string)
+}
+
+var debugMode bool
+
+// SetDebug shows the debug overlay and the log on the screen.
+func SetDebug(on bool) {
+	debugMode = on
+	dw.Display = on
+}
+
+func Debug() bool {
+	return debugMode
+}
+
+// DebugStats is counted from the loaders in the background.
+type DebugStats struct {
+	mutex   sync.Mutex
+	decodes []time.Duration
+	caches  map[string]int
+}
+
+var debugStats = NewDebugStats()
+
+func NewDebugStats() *DebugStats {
+	var s DebugStats
+	s.caches = make(map[string]int)
+	return &s
+}
+
+// Decode records the time to decode and scale a page.
+func (s *DebugStats) Decode(d time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.decodes = append(s.decodes, d)
+	if len(s.decodes) > DebugDecodeSamples {
+		s.decodes = s.decodes[1:]
+	}
+}
+
+// Cache counts the result(memory, disk, miss...) of the cache.
+func (s *DebugStats) Cache(name, result string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.caches[name+" "+result]++
+}
+
+func (s *DebugStats) lines() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var rtn []string
+	if len(s.decodes) > 0 {
+		var sum, max time.Duration
+		for _, d := range s.decodes {
+			sum += d
+			if d > max {
+				max = d
+			}
+		}
+		avg := sum / time.Duration(len(s.decodes))
+		rtn = append(rtn, fmt.Sprintf("decode avg %s max %s", avg.Round(time.Millisecond), max.Round(time.Millisecond)))
+	}
+
+	keys := make([]string, 0, len(s.caches))
+	for k := range s.caches {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		rtn = append(rtn, fmt.Sprintf("%s %d", k, s.caches[k]))
+	}
+	return rtn
+}
+
+// drawDebug draws the bounds of the components and the stats over the scene.
+func drawDebug(screen *ebiten.Image, s Scene) {
+
+	lines := []string{
+		fmt.Sprintf("FPS %0.1f TPS %0.1f", ebiten.CurrentFPS(), ebiten.CurrentTPS()),
+		fmt.Sprintf("scene %T", s),
+	}
+	if i, ok := s.(Inspector); ok {
+		list, info := i.Inspect()
+		drawBounds(screen, list, 0, 0)
+		lines = append(lines, info...)
+	}
+	lines = append(lines, debugStats.lines()...)
+
+	lh := defaultFont.Metrics().Height.Ceil()
+	w := dp(DebugPanelWidth)
+	x := screen.Bounds().Dx() - w
+	ebitenutil.DrawRect(screen, float64(x), 0, float64(w), float64(lh*(len(lines)+1)), theme.Shade)
+	for idx, line := range lines {
+		text.Draw(screen, fitText(line, w-dp(10)), defaultFont, x+dp(5), lh*(idx+1), theme.DebugText)
+	}
+}
+
+// drawBounds draws the outlines, the children are in the coordinates of the container.
+func drawBounds(img *ebiten.Image, list []Component, ox, oy int) {
+	for _, c := range list {
+		fx, fy := c.Point()
+		x, y := ox+int(fx), oy+int(fy)
+		w, h := c.Size()
+		drawOutline(img, x, y, w, h)
+		if con, ok := c.(Container); ok {
+			drawBounds(img, con.Children(), x, y)
+		}
+	}
+}
+
+func drawOutline(img *ebiten.Image, x, y, w, h int) {
+	fx, fy, fw, fh := float64(x), float64(y), float64(w), float64(h)
+	clr := theme.DebugText
+	ebitenutil.DrawRect(img, fx, fy, fw, 1, clr)
+	ebitenutil.DrawRect(img, fx, fy+fh-1, fw, 1, clr)
+	ebitenutil.DrawRect(img, fx, fy, 1, fh, clr)
+	ebitenutil.DrawRect(img, fx+fw-1, fy, 1, fh, clr)
+}
@@ -0,0 +1,39 @@
+package wtv
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// uiScale is the device scale factor at the start.
+// The screen is in the device pixels, the sizes of the components are multiplied by it.
+var uiScale = 1.0
+
+func initScale() {
+	s := ebiten.DeviceScaleFactor()
+	if s <= 0 {
+		s = 1
+	}
+	uiScale = s
+	fonts.SetScale(s)
+}
+
+// dp converts the size for the scale 1 to the device pixels.
+func dp(v int) int {
+	return int(math.Round(float64(v) * uiScale))
+}
+
+func dpf(v float64) float64 {
+	return v * uiScale
+}
+
+// toDevice converts the outside size of the window to the device pixels.
+func toDevice(v int) int {
+	return int(math.Ceil(float64(v) * uiScale))
+}
+
+// fromDevice converts the device pixels to the outside size of the window.
+func fromDevice(v int) int {
+	return int(math.Round(float64(v) / uiScale))
+}
@@ -0,0 +1,169 @@
+package wtv
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/xerrors"
+)
+
+// Dropdown selects one of the options.
+// While it is open, the list is drawn over the other components.
+type Dropdown struct {
+	*Rectangle
+
+	options  []string
+	index    int
+	open     bool
+	hover    int
+	selected bool
+
+	changeFunc func(int) error
+}
+
+func NewDropdown(options []string, x, y, w, h int) *Dropdown {
+	var d Dropdown
+	d.options = options
+	d.hover = -1
+	d.Rectangle = NewRectangle(x, y, w, h)
+	return &d
+}
+
+func (d *Dropdown) Changed(fn func(int) error) {
+	d.changeFunc = fn
+}
+
+func (d *Dropdown) Select(idx int) {
+	if idx < 0 || idx >= len(d.options) {
+		return
+	}
+	d.index = idx
+}
+
+func (d *Dropdown) Selected() int {
+	return d.index
+}
+
+func (d *Dropdown) Set(w, h int) {
+	d.w = w
+	d.h = h
+}
+
+// In includes the list while it is open.
+func (d *Dropdown) In(x, y int) bool {
+	h := d.h
+	if d.open {
+		h += d.h * len(d.options)
+	}
+	return x >= d.x && x < d.x+d.w && y >= d.y && y < d.y+h
+}
+
+// Overlay is drawn and hit at first while it is open.
+func (d *Dropdown) Overlay() bool {
+	return d.open
+}
+
+func (d *Dropdown) SetFocus(f bool) {
+	d.selected = f
+	if !f {
+		d.open = false
+	}
+}
+
+// Activate selects the next option without the mouse.
+func (d *Dropdown) Activate() error {
+	if len(d.options) == 0 {
+		return nil
+	}
+	return d.choose((d.index + 1) % len(d.options))
+}
+
+func (d *Dropdown) choose(idx int) error {
+	d.open = false
+	if idx == d.index {
+		return nil
+	}
+	d.index = idx
+	if d.changeFunc == nil {
+		return nil
+	}
+	err := d.changeFunc(idx)
+	if err != nil {
+		return xerrors.Errorf("changeFunc() error: %w", err)
+	}
+	return nil
+}
+
+// option returns the option index at the y in the component or -1.
+func (d *Dropdown) option(y int) int {
+	if !d.open || y < d.h {
+		return -1
+	}
+	idx := y/d.h - 1
+	if idx >= len(d.options) {
+		return -1
+	}
+	return idx
+}
+
+func (d *Dropdown) HandleEvent(e *Event) error {
+	switch e.Type {
+	case MouseLeaveEvent:
+		d.hover = -1
+	case BlurEvent:
+		d.open = false
+	case ClickEvent:
+		e.Consume()
+		if idx := d.option(e.Y); idx != -1 {
+			return d.choose(idx)
+		}
+		d.open = !d.open
+	}
+	return nil
+}
+
+func (d *Dropdown) Update(x, y int) error {
+	d.hover = -1
+	if d.In(x, y) {
+		d.hover = d.option(y - d.y)
+	}
+	return nil
+}
+
+func (d *Dropdown) Draw(img *ebiten.Image) error {
+
+	x, y := float64(d.x), float64(d.y)
+	w, h := float64(d.w), float64(d.h)
+	th := defaultFont.Metrics().XHeight.Ceil()
+
+	border := color.Color(theme.Button)
+	if d.selected || d.open {
+		border = theme.Active
+	}
+	ebitenutil.DrawRect(img, x, y, w, h, border)
+	ebitenutil.DrawRect(img, x+1, y+1, w-2, h-2, theme.Surface)
+
+	label := ""
+	if d.index < len(d.options) {
+		label = d.options[d.index]
+	}
+	text.Draw(img, fitText(label, d.w-dp(30)), defaultFont, d.x+dp(TextInputPadding), d.y+(d.h+th)/2, theme.Text)
+	text.Draw(img, "v", defaultFont, d.x+d.w-dp(20), d.y+(d.h+th)/2, theme.Button)
+
+	if !d.open {
+		return nil
+	}
+
+	for idx, opt := range d.options {
+		oy := y + h*float64(idx+1)
+		clr := theme.Item
+		if idx == d.hover {
+			clr = theme.Selection
+		}
+		ebitenutil.DrawRect(img, x, oy, w, h, clr)
+		text.Draw(img, fitText(opt, d.w-dp(TextInputPadding)*2), defaultFont, d.x+dp(TextInputPadding), int(oy)+(d.h+th)/2, theme.Text)
+	}
+	return nil
+}
@@ -0,0 +1,306 @@
+package wtv
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"golang.org/x/xerrors"
+)
+
+type EventType int
+
+const (
+	MouseEnterEvent EventType = iota
+	MouseLeaveEvent
+	MouseDownEvent
+	MouseUpEvent
+	ClickEvent
+	KeyDownEvent
+	FocusEvent
+	BlurEvent
+)
+
+func (t EventType) String() string {
+	switch t {
+	case MouseEnterEvent:
+		return "MouseEnter"
+	case MouseLeaveEvent:
+		return "MouseLeave"
+	case MouseDownEvent:
+		return "MouseDown"
+	case MouseUpEvent:
+		return "MouseUp"
+	case ClickEvent:
+		return "Click"
+	case KeyDownEvent:
+		return "KeyDown"
+	case FocusEvent:
+		return "Focus"
+	case BlurEvent:
+		return "Blur"
+	}
+	return "None"
+}
+
+// Event is passed from the target to the parents until it is consumed.
+// X and Y are relative to the component that receives the event.
+type Event struct {
+	Type   EventType
+	X      int
+	Y      int
+	Key    ebiten.Key
+	Target Component
+
+	consumed bool
+}
+
+func (e *Event) Consume() {
+	e.consumed = true
+}
+
+func (e *Event) Consumed() bool {
+	return e.consumed
+}
+
+type EventHandler interface {
+	HandleEvent(*Event) error
+}
+
+// Container has the children in its own coordinates(Point() is the origin).
+type Container interface {
+	Component
+	Children() []Component
+}
+
+// Modal takes all the events while it returns true.
+type Modal interface {
+	Modal() bool
+}
+
+type hitEntry struct {
+	c Component
+	//origin of the parent
+	x int
+	y int
+}
+
+func hitPath(list []Component, x, y, ox, oy int) []hitEntry {
+
+	//the overlay is over the siblings
+	order := make([]Component, 0, len(list))
+	for _, c := range list {
+		if !isOverlay(c) {
+			order = append(order, c)
+		}
+	}
+	for _, c := range list {
+		if isOverlay(c) {
+			order = append(order, c)
+		}
+	}
+
+	for idx := len(order) - 1; idx >= 0; idx-- {
+		c := order[idx]
+		if !c.In(x, y) {
+			continue
+		}
+		path := []hitEntry{{c, ox, oy}}
+		if con, ok := c.(Container); ok {
+			fx, fy := c.Point()
+			px, py := int(fx), int(fy)
+			path = append(path, hitPath(con.Children(), x-px, y-py, ox+px, oy+py)...)
+		}
+		return path
+	}
+	return nil
+}
+
+func findModal(list []Component) Component {
+	for idx := len(list) - 1; idx >= 0; idx-- {
+		if m, ok := list[idx].(Modal); ok && m.Modal() {
+			return list[idx]
+		}
+	}
+	return nil
+}
+
+func contains(path []hitEntry, c Component) bool {
+	for _, e := range path {
+		if e.c == c {
+			return true
+		}
+	}
+	return false
+}
+
+func target(path []hitEntry) Component {
+	if len(path) == 0 {
+		return nil
+	}
+	return path[len(path)-1].c
+}
+
+// EventDispatcher makes the events from the mouse and the keyboard.
+// The last component of the list is the top.
+type EventDispatcher struct {
+	hover []hitEntry
+	down  []hitEntry
+	focus []hitEntry
+	modal bool
+}
+
+func NewEventDispatcher() *EventDispatcher {
+	return &EventDispatcher{}
+}
+
+// Captured is true while the mouse is used by the components.
+func (d *EventDispatcher) Captured() bool {
+	return d.modal || len(d.hover) > 0 || len(d.down) > 0
+}
+
+// Hover returns the component under the cursor.
+func (d *EventDispatcher) Hover() Component {
+	return target(d.hover)
+}
+
+func (d *EventDispatcher) Dispatch(list []Component, x, y int) error {
+
+	if m := findModal(list); m != nil {
+		d.modal = true
+		list = []Component{m}
+	} else {
+		d.modal = false
+	}
+
+	path := hitPath(list, x, y, 0, 0)
+	err := d.hoverPath(path, x, y)
+	if err != nil {
+		return xerrors.Errorf("hoverPath() error: %w", err)
+	}
+
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		d.down = path
+		err = d.send(path, &Event{Type: MouseDownEvent, Target: target(path)}, x, y)
+		if err != nil {
+			return xerrors.Errorf("send(MouseDown) error: %w", err)
+		}
+		err = d.moveFocus(path, x, y)
+		if err != nil {
+			return xerrors.Errorf("moveFocus() error: %w", err)
+		}
+	}
+
+	if inpututil.IsMouseButtonJustReleased(ebiten.MouseButtonLeft) && len(d.down) > 0 {
+		//the mouse up goes to the pressed component
+		down := d.down
+		d.down = nil
+		err = d.send(down, &Event{Type: MouseUpEvent, Target: target(down)}, x, y)
+		if err != nil {
+			return xerrors.Errorf("send(MouseUp) error: %w", err)
+		}
+		if target(down) == target(path) {
+			err = d.send(path, &Event{Type: ClickEvent, Target: target(path)}, x, y)
+			if err != nil {
+				return xerrors.Errorf("send(Click) error: %w", err)
+			}
+		}
+	}
+
+	if len(d.focus) > 0 {
+		for _, k := range inpututil.PressedKeys() {
+			if !inpututil.IsKeyJustPressed(k) {
+				continue
+			}
+			err = d.send(d.focus, &Event{Type: KeyDownEvent, Key: k, Target: target(d.focus)}, x, y)
+			if err != nil {
+				return xerrors.Errorf("send(KeyDown) error: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// hoverPath sends the leave and the enter, they do not bubble.
+func (d *EventDispatcher) hoverPath(path []hitEntry, x, y int) error {
+
+	old := d.hover
+	d.hover = path
+
+	for idx := len(old) - 1; idx >= 0; idx-- {
+		if contains(path, old[idx].c) {
+			continue
+		}
+		err := d.notify(old[idx], MouseLeaveEvent, x, y)
+		if err != nil {
+			return xerrors.Errorf("notify(MouseLeave) error: %w", err)
+		}
+	}
+	for _, e := range path {
+		if contains(old, e.c) {
+			continue
+		}
+		err := d.notify(e, MouseEnterEvent, x, y)
+		if err != nil {
+			return xerrors.Errorf("notify(MouseEnter) error: %w", err)
+		}
+	}
+	return nil
+}
+
+// moveFocus gives the focus to the innermost Focusable of the path.
+func (d *EventDispatcher) moveFocus(path []hitEntry, x, y int) error {
+
+	var focus []hitEntry
+	for idx := len(path) - 1; idx >= 0; idx-- {
+		if _, ok := path[idx].c.(Focusable); ok {
+			focus = path[:idx+1]
+			break
+		}
+	}
+
+	if target(focus) == target(d.focus) {
+		return nil
+	}
+
+	if len(d.focus) > 0 {
+		err := d.notify(d.focus[len(d.focus)-1], BlurEvent, x, y)
+		if err != nil {
+			return xerrors.Errorf("notify(Blur) error: %w", err)
+		}
+	}
+	d.focus = focus
+	if len(focus) > 0 {
+		err := d.notify(focus[len(focus)-1], FocusEvent, x, y)
+		if err != nil {
+			return xerrors.Errorf("notify(Focus) error: %w", err)
+		}
+	}
+	return nil
+}
+
+func (d *EventDispatcher) notify(e hitEntry, t EventType, x, y int) error {
+	h, ok := e.c.(EventHandler)
+	if !ok {
+		return nil
+	}
+	px, py := e.c.Point()
+	return h.HandleEvent(&Event{Type: t, X: x - e.x - int(px), Y: y - e.y - int(py), Target: e.c})
+}
+
+// send passes the event from the innermost component to the outside.
+func (d *EventDispatcher) send(path []hitEntry, ev *Event, x, y int) error {
+	for idx := len(path) - 1; idx >= 0 && !ev.Consumed(); idx-- {
+		e := path[idx]
+		h, ok := e.c.(EventHandler)
+		if !ok {
+			continue
+		}
+		px, py := e.c.Point()
+		ev.X = x - e.x - int(px)
+		ev.Y = y - e.y - int(py)
+		err := h.HandleEvent(ev)
+		if err != nil {
+			return xerrors.Errorf("HandleEvent(%s) error: %w", ev.Type, err)
+		}
+	}
+	return nil
+}
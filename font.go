@@ -0,0 +1,270 @@
+package wtv
+
+import (
+	"image"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/xerrors"
+)
+
+const (
+	FontSmallScale = 0.8
+	FontLargeScale = 1.4
+)
+
+// systemFonts are the fallbacks for the titles in Japanese and Korean.
+// The existing files are used in this order.
+var systemFonts = map[string][]string{
+	"windows": {
+		`C:\Windows\Fonts\meiryo.ttc`,
+		`C:\Windows\Fonts\YuGothM.ttc`,
+		`C:\Windows\Fonts\msgothic.ttc`,
+		`C:\Windows\Fonts\malgun.ttf`,
+	},
+	"darwin": {
+		"/System/Library/Fonts/ヒラギノ角ゴシック W3.ttc",
+		"/System/Library/Fonts/Hiragino Sans GB.ttc",
+		"/System/Library/Fonts/AppleSDGothicNeo.ttc",
+	},
+	"linux": {
+		"/usr/share/fonts/opentype/noto/NotoSansCJK-Regular.ttc",
+		"/usr/share/fonts/noto-cjk/NotoSansCJK-Regular.ttc",
+		"/usr/share/fonts/google-noto-cjk/NotoSansCJK-Regular.ttc",
+		"/usr/share/fonts/truetype/takao-gothic/TakaoGothic.ttf",
+		"/usr/share/fonts/truetype/nanum/NanumGothic.ttf",
+	},
+}
+
+// FontManager makes the faces from the fallback chain.
+// The chain is the user fonts, the embedded font and the system fonts.
+type FontManager struct {
+	files    []string
+	user     []*opentype.Font
+	embedded *opentype.Font
+	system   []*opentype.Font
+	loaded   bool
+
+	scale float64
+	faces map[float64]font.Face
+}
+
+var fonts *FontManager
+
+func NewFontManager(embedded *opentype.Font) *FontManager {
+	var m FontManager
+	m.embedded = embedded
+	m.scale = 1
+	m.faces = make(map[float64]font.Face)
+	return &m
+}
+
+// Load sets the user font files, the system fonts are read at the first time.
+func (m *FontManager) Load(files []string) error {
+
+	var user []*opentype.Font
+	for _, name := range files {
+		if name == "" {
+			continue
+		}
+		f, err := parseFontFile(name)
+		if err != nil {
+			return xerrors.Errorf("parseFontFile(%s) error: %w", name, err)
+		}
+		user = append(user, f)
+	}
+
+	if !m.loaded {
+		m.loaded = true
+		for _, name := range systemFonts[runtime.GOOS] {
+			if _, err := os.Stat(name); err != nil {
+				continue
+			}
+			f, err := parseFontFile(name)
+			if err != nil {
+				logger.Warn("system font error", F("file", name), F("err", err))
+				continue
+			}
+			m.system = append(m.system, f)
+		}
+	}
+
+	m.files = files
+	m.user = user
+	m.clear()
+	return nil
+}
+
+// Loaded is true when the files are the current user fonts.
+func (m *FontManager) Loaded(files []string) bool {
+	if len(files) != len(m.files) {
+		return false
+	}
+	for idx, name := range files {
+		if m.files[idx] != name {
+			return false
+		}
+	}
+	return true
+}
+
+// SetScale is the device scale factor, DPI is multiplied by it.
+func (m *FontManager) SetScale(s float64) {
+	if s <= 0 || s == m.scale {
+		return
+	}
+	m.scale = s
+	m.clear()
+}
+
+func (m *FontManager) clear() {
+	for _, f := range m.faces {
+		f.Close()
+	}
+	m.faces = make(map[float64]font.Face)
+}
+
+func (m *FontManager) chain() []*opentype.Font {
+	rtn := make([]*opentype.Font, 0, len(m.user)+len(m.system)+1)
+	rtn = append(rtn, m.user...)
+	rtn = append(rtn, m.embedded)
+	rtn = append(rtn, m.system...)
+	return rtn
+}
+
+// Face returns the face of the size(point), it is cached.
+func (m *FontManager) Face(size float64) (font.Face, error) {
+
+	if f, ok := m.faces[size]; ok {
+		return f, nil
+	}
+
+	var ff fallbackFace
+	for _, f := range m.chain() {
+		face, err := opentype.NewFace(f, &opentype.FaceOptions{
+			Size:    size,
+			DPI:     defaultFontDPI * m.scale,
+			Hinting: font.HintingFull,
+		})
+		if err != nil {
+			return nil, xerrors.Errorf("opentype.NewFace() error: %w", err)
+		}
+		ff.fonts = append(ff.fonts, f)
+		ff.faces = append(ff.faces, face)
+	}
+	ff.index = make(map[rune]int)
+
+	m.faces[size] = &ff
+	return &ff, nil
+}
+
+func parseFontFile(name string) (*opentype.Font, error) {
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, xerrors.Errorf("os.ReadFile() error: %w", err)
+	}
+
+	ext := strings.ToLower(filepath.Ext(name))
+	if ext != ".ttc" && ext != ".otc" {
+		f, err := opentype.Parse(data)
+		if err != nil {
+			return nil, xerrors.Errorf("opentype.Parse() error: %w", err)
+		}
+		return f, nil
+	}
+
+	//the first font of the collection
+	c, err := opentype.ParseCollection(data)
+	if err != nil {
+		return nil, xerrors.Errorf("opentype.ParseCollection() error: %w", err)
+	}
+	f, err := c.Font(0)
+	if err != nil {
+		return nil, xerrors.Errorf("Collection.Font() error: %w", err)
+	}
+	return f, nil
+}
+
+// fallbackFace uses the first face that has the glyph.
+// The metrics are of the first face.
+type fallbackFace struct {
+	fonts []*opentype.Font
+	faces []font.Face
+	index map[rune]int
+	buf   sfnt.Buffer
+}
+
+func (f *fallbackFace) face(r rune) font.Face {
+	idx, ok := f.index[r]
+	if !ok {
+		idx = 0
+		for i, elm := range f.fonts {
+			g, err := elm.GlyphIndex(&f.buf, r)
+			if err == nil && g != 0 {
+				idx = i
+				break
+			}
+		}
+		f.index[r] = idx
+	}
+	return f.faces[idx]
+}
+
+func (f *fallbackFace) Close() error {
+	for _, face := range f.faces {
+		face.Close()
+	}
+	return nil
+}
+
+func (f *fallbackFace) Glyph(dot fixed.Point26_6, r rune) (image.Rectangle, image.Image, image.Point, fixed.Int26_6, bool) {
+	return f.face(r).Glyph(dot, r)
+}
+
+func (f *fallbackFace) GlyphBounds(r rune) (fixed.Rectangle26_6, fixed.Int26_6, bool) {
+	return f.face(r).GlyphBounds(r)
+}
+
+func (f *fallbackFace) GlyphAdvance(r rune) (fixed.Int26_6, bool) {
+	return f.face(r).GlyphAdvance(r)
+}
+
+// Kern is only between the glyphs of the same face.
+func (f *fallbackFace) Kern(r0, r1 rune) fixed.Int26_6 {
+	face := f.face(r0)
+	if face != f.face(r1) {
+		return 0
+	}
+	return face.Kern(r0, r1)
+}
+
+func (f *fallbackFace) Metrics() font.Metrics {
+	return f.faces[0].Metrics()
+}
+
+// fontFace is the face of the theme size multiplied by the scale.
+func fontFace(scale float64) font.Face {
+	face, err := fonts.Face(theme.FontSize * scale)
+	if err != nil {
+		logger.Error("font face error", F("size", theme.FontSize*scale), F("err", err))
+		return defaultFont
+	}
+	return face
+}
+
+// setFontSize makes the default font face by the size of the theme.
+func setFontSize(size float64) error {
+	face, err := fonts.Face(size)
+	if err != nil {
+		return xerrors.Errorf("Face() error: %w", err)
+	}
+	defaultFont = face
+	return nil
+}
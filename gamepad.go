@@ -0,0 +1,133 @@
+package wtv
+
+import (
+	"math"
+	"wtv/config"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// InputSource is the gamepad part of ebiten.
+// Replace it to drive the Gamepad without a device.
+type InputSource interface {
+	GamepadIDs() []ebiten.GamepadID
+	GamepadAxis(ebiten.GamepadID, int) float64
+	IsGamepadButtonJustPressed(ebiten.GamepadID, ebiten.GamepadButton) bool
+}
+
+type ebitenInput struct{}
+
+func (ebitenInput) GamepadIDs() []ebiten.GamepadID {
+	return ebiten.GamepadIDs()
+}
+
+func (ebitenInput) GamepadAxis(id ebiten.GamepadID, axis int) float64 {
+	return ebiten.GamepadAxis(id, axis)
+}
+
+func (ebitenInput) IsGamepadButtonJustPressed(id ebiten.GamepadID, b ebiten.GamepadButton) bool {
+	return inpututil.IsGamepadButtonJustPressed(id, b)
+}
+
+type GamepadAction int
+
+const (
+	GamepadPrevPage GamepadAction = iota
+	GamepadNextPage
+	GamepadPrevChapter
+	GamepadNextChapter
+	GamepadMenu
+	GamepadFocusPrev
+	GamepadFocusNext
+	GamepadActivate
+)
+
+type Gamepad struct {
+	input   InputSource
+	mapping config.GamepadMapping
+}
+
+func NewGamepad(in InputSource, m config.GamepadMapping) *Gamepad {
+	var g Gamepad
+	g.input = in
+	if g.input == nil {
+		g.input = ebitenInput{}
+	}
+	g.mapping = m
+	return &g
+}
+
+func (g *Gamepad) SetMapping(m config.GamepadMapping) {
+	g.mapping = m
+}
+
+func (g *Gamepad) button(a GamepadAction) ebiten.GamepadButton {
+	m := g.mapping
+	b := -1
+	switch a {
+	case GamepadPrevPage:
+		b = m.PrevPage
+	case GamepadNextPage:
+		b = m.NextPage
+	case GamepadPrevChapter:
+		b = m.PrevChapter
+	case GamepadNextChapter:
+		b = m.NextChapter
+	case GamepadMenu:
+		b = m.Menu
+	case GamepadFocusPrev:
+		b = m.FocusPrev
+	case GamepadFocusNext:
+		b = m.FocusNext
+	case GamepadActivate:
+		b = m.Activate
+	}
+	return ebiten.GamepadButton(b)
+}
+
+// JustPressed reports whether any connected gamepad pressed the action button in this tick.
+func (g *Gamepad) JustPressed(a GamepadAction) bool {
+	b := g.button(a)
+	if b < 0 || b > ebiten.GamepadButtonMax {
+		return false
+	}
+	for _, id := range g.input.GamepadIDs() {
+		if g.input.IsGamepadButtonJustPressed(id, b) {
+			return true
+		}
+	}
+	return false
+}
+
+// Scroll returns the scroll axis in -1..1. The deadzone is cut and the rest is rescaled.
+func (g *Gamepad) Scroll() float64 {
+
+	dz := g.mapping.Deadzone
+	if dz < 0 || dz >= 1 {
+		dz = 0
+	}
+
+	rtn := 0.0
+	for _, id := range g.input.GamepadIDs() {
+		v := g.input.GamepadAxis(id, g.mapping.ScrollAxis)
+		if math.Abs(v) > math.Abs(rtn) {
+			rtn = v
+		}
+	}
+
+	a := math.Abs(rtn)
+	if a <= dz {
+		return 0
+	}
+	a = (a - dz) / (1 - dz)
+	if a > 1 {
+		a = 1
+	}
+	return math.Copysign(a, rtn)
+}
+
+// ScrollPixel is Scroll() in pixels for one tick.
+func (g *Gamepad) ScrollPixel() int {
+	return int(g.Scroll() * dpf(float64(g.mapping.ScrollSpeed)))
+}
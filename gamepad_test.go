@@ -0,0 +1,144 @@
+package wtv
+
+import (
+	"testing"
+	"wtv/config"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// fakeInput is one gamepad, pressed is the buttons pressed in this tick.
+type fakeInput struct {
+	axes    map[int]float64
+	pressed map[ebiten.GamepadButton]bool
+}
+
+func newFakeInput() *fakeInput {
+	return &fakeInput{
+		axes:    make(map[int]float64),
+		pressed: make(map[ebiten.GamepadButton]bool),
+	}
+}
+
+func (f *fakeInput) GamepadIDs() []ebiten.GamepadID {
+	return []ebiten.GamepadID{0}
+}
+
+func (f *fakeInput) GamepadAxis(id ebiten.GamepadID, axis int) float64 {
+	return f.axes[axis]
+}
+
+func (f *fakeInput) IsGamepadButtonJustPressed(id ebiten.GamepadID, b ebiten.GamepadButton) bool {
+	return f.pressed[b]
+}
+
+// defaultMapping is the mapping of the default config.
+func defaultMapping() config.GamepadMapping {
+	return config.Get().Gamepad
+}
+
+func TestGamepadDeadzone(t *testing.T) {
+
+	in := newFakeInput()
+	g := NewGamepad(in, defaultMapping())
+
+	in.axes[1] = 0.1
+	if v := g.Scroll(); v != 0 {
+		t.Errorf("axis 0.1 want 0 got %v", v)
+	}
+
+	in.axes[1] = 0.5
+	if v := g.Scroll(); v <= 0 {
+		t.Errorf("axis 0.5 want > 0 got %v", v)
+	}
+
+	in.axes[1] = -0.5
+	if v := g.Scroll(); v >= 0 {
+		t.Errorf("axis -0.5 want < 0 got %v", v)
+	}
+
+	//other axes are not the scroll
+	in.axes[1] = 0
+	in.axes[0] = 1
+	if v := g.Scroll(); v != 0 {
+		t.Errorf("axis 0 want 0 got %v", v)
+	}
+}
+
+func TestGamepadButtons(t *testing.T) {
+
+	m := defaultMapping()
+	tests := []struct {
+		action GamepadAction
+		button int
+	}{
+		{GamepadPrevPage, m.PrevPage},
+		{GamepadNextPage, m.NextPage},
+		{GamepadPrevChapter, m.PrevChapter},
+		{GamepadNextChapter, m.NextChapter},
+		{GamepadMenu, m.Menu},
+		{GamepadFocusPrev, m.FocusPrev},
+		{GamepadFocusNext, m.FocusNext},
+		{GamepadActivate, m.Activate},
+	}
+
+	in := newFakeInput()
+	g := NewGamepad(in, m)
+
+	for _, test := range tests {
+
+		in.pressed = map[ebiten.GamepadButton]bool{ebiten.GamepadButton(test.button): true}
+		for _, other := range tests {
+			got := g.JustPressed(other.action)
+			if want := other.action == test.action; got != want {
+				t.Errorf("button %d action %d want %t got %t", test.button, other.action, want, got)
+			}
+		}
+
+		//held in the next tick is not just pressed
+		in.pressed = map[ebiten.GamepadButton]bool{}
+		if g.JustPressed(test.action) {
+			t.Errorf("action %d is triggered twice", test.action)
+		}
+	}
+}
+
+func TestGamepadCustomMapping(t *testing.T) {
+
+	m := defaultMapping()
+	m.PrevPage = 3
+	m.ScrollAxis = 2
+	m.Deadzone = 0.6
+
+	in := newFakeInput()
+	g := NewGamepad(in, m)
+
+	in.pressed[10] = true
+	if g.JustPressed(GamepadPrevPage) {
+		t.Errorf("default button 10 is still the prev page")
+	}
+	in.pressed = map[ebiten.GamepadButton]bool{3: true}
+	if !g.JustPressed(GamepadPrevPage) {
+		t.Errorf("button 3 is not the prev page")
+	}
+
+	in.axes[1] = 1
+	if v := g.Scroll(); v != 0 {
+		t.Errorf("default axis 1 want 0 got %v", v)
+	}
+	in.axes[2] = 0.5
+	if v := g.Scroll(); v != 0 {
+		t.Errorf("axis 0.5 in deadzone 0.6 want 0 got %v", v)
+	}
+	in.axes[2] = 0.8
+	if v := g.Scroll(); v <= 0 {
+		t.Errorf("axis 0.8 want > 0 got %v", v)
+	}
+
+	//SetMapping replaces the mapping
+	g.SetMapping(defaultMapping())
+	in.pressed = map[ebiten.GamepadButton]bool{10: true}
+	if !g.JustPressed(GamepadPrevPage) {
+		t.Errorf("button 10 is not the prev page after SetMapping")
+	}
+}
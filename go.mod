@@ -3,12 +3,11 @@ module wtv
 go 1.16
 
 require (
-	github.com/fogleman/gg v1.3.0 // indirect
-	github.com/hajimehoshi/ebiten v1.12.12 // indirect
+	github.com/fogleman/gg v1.3.0
+	github.com/golang/freetype v0.0.0-20170609003504-e2365dfdc4a0 // indirect
 	github.com/hajimehoshi/ebiten/v2 v2.1.2
-	github.com/sqweek/dialog v0.0.0-20200911184034-8a3d98e8211d // indirect
 	golang.org/x/exp v0.0.0-20210526181343-b47a03e3048a // indirect
-	golang.org/x/image v0.0.0-20210504121937-7319ad40d33e // indirect
+	golang.org/x/image v0.0.0-20210504121937-7319ad40d33e
 	golang.org/x/mobile v0.0.0-20210527171505-7e972142eb43 // indirect
 	golang.org/x/sys v0.0.0-20210601080250-7ecdf8ef093b // indirect
 	golang.org/x/xerrors v0.0.0-20200804184101-5ec99f83aff1
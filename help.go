@@ -0,0 +1,81 @@
+package wtv
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+const HelpLineHeight = 28
+
+var helpLines = []string{
+	"Wheel / Drag      Scroll",
+	"Top edge          Menu",
+	"Right edge        Minimap",
+	"Bottom edge       Position slider",
+	"G                 Pages",
+	"P                 Problems",
+	"F1                Help",
+	"F3                Debug",
+	"Esc / Backspace   Close / Up (Library)",
+}
+
+// Help is the overlay of the controls.
+type Help struct {
+	scenes *SceneManager
+	width  int
+	height int
+}
+
+func NewHelp() *Help {
+	return &Help{}
+}
+
+func (h *Help) Enter(m *SceneManager) error {
+	h.scenes = m
+	return nil
+}
+
+func (h *Help) Leave() error {
+	return nil
+}
+
+func (h *Help) Overlay() bool {
+	return true
+}
+
+func (h *Help) Close() error {
+	if h.scenes == nil {
+		return nil
+	}
+	return h.scenes.Remove(h)
+}
+
+func (h *Help) Update(w, ht int) error {
+
+	h.width, h.height = w, ht
+
+	//any key or click closes
+	for _, k := range inpututil.PressedKeys() {
+		if inpututil.IsKeyJustPressed(k) {
+			return h.Close()
+		}
+	}
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		return h.Close()
+	}
+	return nil
+}
+
+func (h *Help) Draw(screen *ebiten.Image) error {
+
+	ebitenutil.DrawRect(screen, 0, 0, float64(h.width), float64(h.height), theme.Shade)
+
+	y := (h.height - len(helpLines)*dp(HelpLineHeight)) / 2
+	x := h.width/2 - dp(180)
+	for idx, line := range helpLines {
+		text.Draw(screen, line, defaultFont, x, y+idx*dp(HelpLineHeight), theme.Text)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package wtv
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// Label is the text in the rectangle, it is cut to the width.
+type Label struct {
+	*Rectangle
+	text  string
+	color color.Color
+	//font size to the theme
+	scale float64
+}
+
+func NewLabel(txt string, x, y, w, h int) *Label {
+	var l Label
+	l.Rectangle = NewRectangle(x, y, w, h)
+	l.text = txt
+	//follows the theme
+	l.color = &theme.Text
+	l.scale = 1
+	return &l
+}
+
+func (l *Label) SetText(txt string) {
+	l.text = txt
+}
+
+func (l *Label) SetColor(clr color.Color) {
+	l.color = clr
+}
+
+// SetScale changes the font size, FontLargeScale...
+func (l *Label) SetScale(s float64) {
+	l.scale = s
+}
+
+func (l *Label) Set(w, h int) {
+	l.w = w
+	l.h = h
+}
+
+func (l *Label) Update(x, y int) error {
+	return nil
+}
+
+func (l *Label) Draw(img *ebiten.Image) error {
+	face := defaultFont
+	if l.scale != 1 {
+		face = fontFace(l.scale)
+	}
+	th := face.Metrics().XHeight.Ceil()
+	text.Draw(img, fitFaceText(face, l.text, l.w), face, l.x, l.y+(l.h+th)/2, l.color)
+	return nil
+}
@@ -1,5 +1,279 @@
 package wtv
 
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Layout receives the size given by the parent.
 type Layout interface {
 	Set(int, int)
 }
+
+// LayoutManager positions the children of Components in the area.
+type LayoutManager interface {
+	Arrange(children []Component, x, y, w, h int)
+}
+
+type Padding struct {
+	Top    int
+	Right  int
+	Bottom int
+	Left   int
+}
+
+func NewPadding(v int) Padding {
+	return Padding{v, v, v, v}
+}
+
+type Align int
+
+const (
+	AlignStart Align = iota
+	AlignCenter
+	AlignEnd
+	AlignStretch
+)
+
+// place moves the component to the top-left and gives the size if it is changed.
+func place(c Component, x, y, w, h int) {
+	c.Move(x, y)
+	if pw, ph := c.Size(); pw != w || ph != h {
+		c.Set(w, h)
+	}
+}
+
+func alignOffset(a Align, space int) int {
+	switch a {
+	case AlignCenter:
+		return space / 2
+	case AlignEnd:
+		return space
+	}
+	return 0
+}
+
+type growEntry struct {
+	weight int
+	//size before growing
+	base int
+}
+
+// BoxLayout lines up the children horizontally or vertically.
+// The rest of the space is shared by the children that have grow.
+type BoxLayout struct {
+	vertical bool
+	grow     map[Component]growEntry
+
+	Spacing int
+	Padding Padding
+	//main axis(when no child grows)
+	Justify Align
+	//cross axis
+	Align Align
+}
+
+func NewHBox() *BoxLayout {
+	var b BoxLayout
+	b.grow = make(map[Component]growEntry)
+	b.Align = AlignCenter
+	return &b
+}
+
+func NewVBox() *BoxLayout {
+	b := NewHBox()
+	b.vertical = true
+	b.Align = AlignStart
+	return b
+}
+
+// Grow sets the weight of the rest of the space.
+// The current size of the component is the minimum.
+func (b *BoxLayout) Grow(c Component, g int) {
+	m, _ := b.axis(c.Size())
+	b.grow[c] = growEntry{g, m}
+}
+
+// length is the main axis length before growing.
+func (b *BoxLayout) length(c Component) (int, int) {
+	m, cr := b.axis(c.Size())
+	if e, ok := b.grow[c]; ok {
+		m = e.base
+	}
+	return m, cr
+}
+
+// axis returns the main and the cross length.
+func (b *BoxLayout) axis(w, h int) (int, int) {
+	if b.vertical {
+		return h, w
+	}
+	return w, h
+}
+
+func (b *BoxLayout) Arrange(children []Component, x, y, w, h int) {
+
+	if len(children) == 0 {
+		return
+	}
+
+	x += b.Padding.Left
+	y += b.Padding.Top
+	w -= b.Padding.Left + b.Padding.Right
+	h -= b.Padding.Top + b.Padding.Bottom
+
+	main, cross := b.axis(w, h)
+
+	total := b.Spacing * (len(children) - 1)
+	weights := 0
+	for _, c := range children {
+		m, _ := b.length(c)
+		total += m
+		weights += b.grow[c].weight
+	}
+
+	rest := main - total
+	pos := 0
+	if weights == 0 || rest < 0 {
+		pos = alignOffset(b.Justify, rest)
+	}
+
+	for _, c := range children {
+
+		m, cr := b.length(c)
+		if g := b.grow[c].weight; g > 0 && rest > 0 {
+			m += rest * g / weights
+		}
+
+		off := alignOffset(b.Align, cross-cr)
+		if b.Align == AlignStretch {
+			cr = cross
+			off = 0
+		}
+
+		if b.vertical {
+			place(c, x+off, y+pos, cr, m)
+		} else {
+			place(c, x+pos, y+off, m, cr)
+		}
+		pos += m + b.Spacing
+	}
+}
+
+// FormLayout arranges the children in pairs of the label and the field.
+// The field keeps its height and takes the rest of the width.
+type FormLayout struct {
+	LabelWidth int
+	RowHeight  int
+	Spacing    int
+	Padding    Padding
+}
+
+func NewFormLayout(labelWidth, rowHeight int) *FormLayout {
+	var f FormLayout
+	f.LabelWidth = labelWidth
+	f.RowHeight = rowHeight
+	return &f
+}
+
+func (f *FormLayout) Arrange(children []Component, x, y, w, h int) {
+
+	x += f.Padding.Left
+	y += f.Padding.Top
+	w -= f.Padding.Left + f.Padding.Right
+
+	for idx := 0; idx < len(children); idx += 2 {
+		ry := y + (idx/2)*(f.RowHeight+f.Spacing)
+		place(children[idx], x, ry, f.LabelWidth, f.RowHeight)
+		if idx+1 >= len(children) {
+			break
+		}
+		field := children[idx+1]
+		_, fh := field.Size()
+		place(field, x+f.LabelWidth, ry+(f.RowHeight-fh)/2, w-f.LabelWidth, fh)
+	}
+}
+
+type Anchor int
+
+const (
+	AnchorLeft Anchor = 1 << iota
+	AnchorRight
+	AnchorTop
+	AnchorBottom
+)
+
+type anchorEntry struct {
+	anchor Anchor
+	dx     int
+	dy     int
+}
+
+// AnchorLayout fixes each child to the sides of the area.
+// Both sides stretch the child, no side centers it.
+type AnchorLayout struct {
+	entries map[Component]anchorEntry
+	Padding Padding
+}
+
+func NewAnchorLayout() *AnchorLayout {
+	var a AnchorLayout
+	a.entries = make(map[Component]anchorEntry)
+	return &a
+}
+
+// Anchor sets the sides and the margin from them.
+func (a *AnchorLayout) Anchor(c Component, an Anchor, dx, dy int) {
+	a.entries[c] = anchorEntry{an, dx, dy}
+}
+
+func anchorAxis(start, end bool, pos, length, size, margin int) (int, int) {
+	switch {
+	case start && end:
+		return pos + margin, length - margin*2
+	case end:
+		return pos + length - size - margin, size
+	case start:
+		return pos + margin, size
+	}
+	return pos + (length-size)/2, size
+}
+
+func (a *AnchorLayout) Arrange(children []Component, x, y, w, h int) {
+
+	x += a.Padding.Left
+	y += a.Padding.Top
+	w -= a.Padding.Left + a.Padding.Right
+	h -= a.Padding.Top + a.Padding.Bottom
+
+	for _, c := range children {
+		e := a.entries[c]
+		cw, ch := c.Size()
+		cx, cw := anchorAxis(e.anchor&AnchorLeft != 0, e.anchor&AnchorRight != 0, x, w, cw, e.dx)
+		cy, ch := anchorAxis(e.anchor&AnchorTop != 0, e.anchor&AnchorBottom != 0, y, h, ch, e.dy)
+		place(c, cx, cy, cw, ch)
+	}
+}
+
+// Spacer is an empty component to make the space in the layout.
+type Spacer struct {
+	*Rectangle
+}
+
+func NewSpacer(w, h int) *Spacer {
+	var s Spacer
+	s.Rectangle = NewRectangle(0, 0, w, h)
+	return &s
+}
+
+func (s *Spacer) Set(w, h int) {
+	s.w = w
+	s.h = h
+}
+
+func (s *Spacer) Update(x, y int) error {
+	return nil
+}
+
+func (s *Spacer) Draw(img *ebiten.Image) error {
+	return nil
+}
@@ -0,0 +1,281 @@
+package wtv
+
+import (
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/xerrors"
+)
+
+const (
+	ScrollbarWidth   = 8
+	ScrollWheelSpeed = 40
+	DoubleClickTicks = 20
+)
+
+// RowDrawer draws the row in the rectangle.
+type RowDrawer func(img *ebiten.Image, idx int, r image.Rectangle, selected bool)
+
+// TextRows draws the items as the text.
+func TextRows(items []string) RowDrawer {
+	return func(img *ebiten.Image, idx int, r image.Rectangle, selected bool) {
+		if idx >= len(items) {
+			return
+		}
+		th := defaultFont.Metrics().XHeight.Ceil()
+		text.Draw(img, fitText(items[idx], r.Dx()-dp(TextInputPadding)*2), defaultFont,
+			r.Min.X+dp(TextInputPadding), r.Min.Y+(r.Dy()+th)/2, theme.Text)
+	}
+}
+
+// List is a scrollable list, only the visible rows are drawn.
+type List struct {
+	*Rectangle
+
+	count     int
+	rowHeight int
+	scroll    int
+	index     int
+	hover     int
+
+	hovering bool
+	selected bool
+
+	ticks     int
+	lastClick int
+
+	drawRow      RowDrawer
+	changeFunc   func(int) error
+	activateFunc func(int) error
+}
+
+func NewList(x, y, w, h, rowHeight int) *List {
+	var l List
+	l.Rectangle = NewRectangle(x, y, w, h)
+	l.rowHeight = rowHeight
+	l.index = -1
+	l.hover = -1
+	return &l
+}
+
+// SetRows sets the number of the rows and the drawer.
+func (l *List) SetRows(count int, fn RowDrawer) {
+	l.count = count
+	l.drawRow = fn
+	if l.index >= count {
+		l.index = -1
+	}
+	l.clamp()
+}
+
+func (l *List) Count() int {
+	return l.count
+}
+
+// Changed is called when the selected row is changed by the user.
+func (l *List) Changed(fn func(int) error) {
+	l.changeFunc = fn
+}
+
+// Activated is called by the double click or the enter key.
+func (l *List) Activated(fn func(int) error) {
+	l.activateFunc = fn
+}
+
+func (l *List) Select(idx int) {
+	if idx < -1 || idx >= l.count {
+		return
+	}
+	l.index = idx
+	l.ScrollTo(idx)
+}
+
+func (l *List) Selected() int {
+	return l.index
+}
+
+func (l *List) Set(w, h int) {
+	l.w = w
+	l.h = h
+	l.clamp()
+}
+
+func (l *List) SetFocus(f bool) {
+	l.selected = f
+}
+
+func (l *List) Activate() error {
+	if l.index == -1 || l.activateFunc == nil {
+		return nil
+	}
+	err := l.activateFunc(l.index)
+	if err != nil {
+		return xerrors.Errorf("activateFunc() error: %w", err)
+	}
+	return nil
+}
+
+// ScrollTo makes the row visible.
+func (l *List) ScrollTo(idx int) {
+	if idx < 0 {
+		return
+	}
+	top := idx * l.rowHeight
+	if top < l.scroll {
+		l.scroll = top
+	}
+	if top+l.rowHeight > l.scroll+l.h {
+		l.scroll = top + l.rowHeight - l.h
+	}
+	l.clamp()
+}
+
+func (l *List) clamp() {
+	max := l.count*l.rowHeight - l.h
+	if l.scroll > max {
+		l.scroll = max
+	}
+	if l.scroll < 0 {
+		l.scroll = 0
+	}
+}
+
+// row returns the row at the y in the component or -1.
+func (l *List) row(y int) int {
+	if y < 0 || y >= l.h {
+		return -1
+	}
+	idx := (y + l.scroll) / l.rowHeight
+	if idx >= l.count {
+		return -1
+	}
+	return idx
+}
+
+func (l *List) change(idx int) error {
+	if idx == l.index {
+		return nil
+	}
+	l.Select(idx)
+	if l.changeFunc == nil {
+		return nil
+	}
+	err := l.changeFunc(idx)
+	if err != nil {
+		return xerrors.Errorf("changeFunc() error: %w", err)
+	}
+	return nil
+}
+
+func (l *List) HandleEvent(e *Event) error {
+
+	switch e.Type {
+	case MouseEnterEvent:
+		l.hovering = true
+	case MouseLeaveEvent:
+		l.hovering = false
+		l.hover = -1
+	case FocusEvent:
+		l.selected = true
+	case BlurEvent:
+		l.selected = false
+	case ClickEvent:
+		idx := l.row(e.Y)
+		if idx == -1 {
+			return nil
+		}
+		e.Consume()
+		double := idx == l.index && l.ticks-l.lastClick < DoubleClickTicks
+		l.lastClick = l.ticks
+		if double {
+			return l.Activate()
+		}
+		return l.change(idx)
+	case KeyDownEvent:
+		switch e.Key {
+		case ebiten.KeyUp:
+			e.Consume()
+			if l.index > 0 {
+				return l.change(l.index - 1)
+			}
+		case ebiten.KeyDown:
+			e.Consume()
+			if l.index+1 < l.count {
+				return l.change(l.index + 1)
+			}
+		case ebiten.KeyEnter:
+			e.Consume()
+			return l.Activate()
+		}
+	}
+	return nil
+}
+
+func (l *List) Update(x, y int) error {
+
+	l.ticks++
+	if !l.hovering {
+		return nil
+	}
+
+	l.hover = l.row(y - l.y)
+	_, dy := ebiten.Wheel()
+	if dy != 0 {
+		l.scroll -= int(dy * float64(dp(ScrollWheelSpeed)))
+		l.clamp()
+	}
+	return nil
+}
+
+func (l *List) Draw(img *ebiten.Image) error {
+
+	r := image.Rect(l.x, l.y, l.x+l.w, l.y+l.h)
+	dst := img.SubImage(r).(*ebiten.Image)
+	ebitenutil.DrawRect(dst, float64(l.x), float64(l.y), float64(l.w), float64(l.h), theme.Surface)
+
+	w := l.w
+	if l.count*l.rowHeight > l.h {
+		w -= dp(ScrollbarWidth)
+	}
+
+	first := l.scroll / l.rowHeight
+	last := (l.scroll + l.h) / l.rowHeight
+	for idx := first; idx <= last && idx < l.count; idx++ {
+
+		y := l.y + idx*l.rowHeight - l.scroll
+		row := image.Rect(l.x, y, l.x+w, y+l.rowHeight)
+
+		switch {
+		case idx == l.index:
+			ebitenutil.DrawRect(dst, float64(row.Min.X), float64(row.Min.Y), float64(w), float64(l.rowHeight), theme.Selection)
+		case idx == l.hover:
+			ebitenutil.DrawRect(dst, float64(row.Min.X), float64(row.Min.Y), float64(w), float64(l.rowHeight), theme.Item)
+		}
+		if l.drawRow != nil {
+			l.drawRow(dst, idx, row, idx == l.index)
+		}
+	}
+
+	drawScrollbar(dst, l.x+l.w-dp(ScrollbarWidth), l.y, l.h, l.scroll, l.count*l.rowHeight)
+
+	if l.selected {
+		ebitenutil.DrawRect(dst, float64(l.x), float64(l.y), float64(l.w), 1, theme.Active)
+	}
+	return nil
+}
+
+// drawScrollbar draws the bar when the content is longer than the view.
+func drawScrollbar(img *ebiten.Image, x, y, h, scroll, content int) {
+	if content <= h {
+		return
+	}
+	bh := h * h / content
+	if bh < dp(ScrollbarWidth)*2 {
+		bh = dp(ScrollbarWidth) * 2
+	}
+	by := y + (h-bh)*scroll/(content-h)
+	ebitenutil.DrawRect(img, float64(x), float64(y), float64(dp(ScrollbarWidth)), float64(h), theme.Scrollbar)
+	ebitenutil.DrawRect(img, float64(x), float64(by), float64(dp(ScrollbarWidth)), float64(bh), theme.Button)
+}
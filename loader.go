@@ -0,0 +1,121 @@
+package wtv
+
+import (
+	"image"
+	"sync"
+	"time"
+	"wtv/book"
+
+	"golang.org/x/xerrors"
+)
+
+const PageLoaderQueueSize = 64
+
+type pageJob struct {
+	generation int
+	index      int
+}
+
+type loadedPage struct {
+	index int
+	img   image.Image
+	err   error
+}
+
+// PageLoader decodes and resizes the pages of the book in the background.
+// The results are collected in the game loop.
+type PageLoader struct {
+	queue   chan pageJob
+	resize  func(image.Image, int) image.Image
+	pending map[int]bool
+
+	mutex      sync.Mutex
+	book       *book.Book
+	width      int
+	generation int
+	results    []loadedPage
+}
+
+func NewPageLoader(resize func(image.Image, int) image.Image) *PageLoader {
+	var l PageLoader
+	l.queue = make(chan pageJob, PageLoaderQueueSize)
+	l.resize = resize
+	l.pending = make(map[int]bool)
+	go l.work()
+	return &l
+}
+
+func (l *PageLoader) work() {
+	for job := range l.queue {
+
+		l.mutex.Lock()
+		b, w, gen := l.book, l.width, l.generation
+		l.mutex.Unlock()
+		if job.generation != gen || b == nil {
+			continue
+		}
+
+		page := loadedPage{index: job.index}
+		start := time.Now()
+		img, err := b.Load(job.index)
+		if err != nil {
+			page.err = xerrors.Errorf("Book Load(%d) error: %w", job.index, err)
+		} else {
+			page.img = l.resize(img, w)
+			debugStats.Decode(time.Since(start))
+		}
+
+		l.mutex.Lock()
+		if gen == l.generation {
+			l.results = append(l.results, page)
+		}
+		l.mutex.Unlock()
+	}
+}
+
+// Set changes the book and the width.
+// It returns true when the loaded pages are no longer valid.
+func (l *PageLoader) Set(b *book.Book, w int) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.book == b && l.width == w {
+		return false
+	}
+	l.generation++
+	l.book = b
+	l.width = w
+	l.results = nil
+	l.pending = make(map[int]bool)
+	return true
+}
+
+// Request queues the page. It does not block, when the queue is full it is retried by the next call.
+func (l *PageLoader) Request(idx int) {
+
+	if l.pending[idx] {
+		return
+	}
+
+	l.mutex.Lock()
+	gen := l.generation
+	l.mutex.Unlock()
+
+	select {
+	case l.queue <- pageJob{gen, idx}:
+		l.pending[idx] = true
+	default:
+	}
+}
+
+func (l *PageLoader) Collect() []loadedPage {
+	l.mutex.Lock()
+	results := l.results
+	l.results = nil
+	l.mutex.Unlock()
+
+	for _, page := range results {
+		delete(l.pending, page.index)
+	}
+	return results
+}
@@ -1,41 +1,315 @@
 package wtv
 
 import (
-	"image/color"
-	"log"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
+	"wtv/book"
+	"wtv/config"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/text"
 	"golang.org/x/xerrors"
 )
 
-var logger *log.Logger
+const (
+	LogFileName    = "wtv.log"
+	LogFileMaxSize = 1 << 20
+	LogFileBackups = 3
+	LogTimeFormat  = "2006-01-02 15:04:05.000"
+)
+
+type Level int
+
+const (
+	DebugLevel Level = iota
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+)
+
+func (l Level) String() string {
+	switch l {
+	case DebugLevel:
+		return "DEBUG"
+	case InfoLevel:
+		return "INFO"
+	case WarnLevel:
+		return "WARN"
+	case ErrorLevel:
+		return "ERROR"
+	}
+	return "None"
+}
+
+// ParseLevel is the level of the name(debug, info, warn, error).
+func ParseLevel(name string) (Level, error) {
+	for l := DebugLevel; l <= ErrorLevel; l++ {
+		if strings.EqualFold(name, l.String()) {
+			return l, nil
+		}
+	}
+	return InfoLevel, xerrors.Errorf("unknown level[%s]", name)
+}
+
+// Field is the structured value of the log.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+func F(key string, v interface{}) Field {
+	return Field{key, v}
+}
+
+type logSink struct {
+	w     io.Writer
+	level Level
+}
+
+// Logger writes a line to the sinks of the level or less.
+type Logger struct {
+	mutex sync.Mutex
+	sinks map[string]*logSink
+}
+
+var logger *Logger
 var dw *DisplayWriter
 
+// logLevel is the level of stderr and the file.
+var logLevel = InfoLevel
+
 func init() {
 	dw = NewDisplayWriter()
-	logger = log.New(dw, "", 0)
+	logger = NewLogger()
+	logger.AddSink("stderr", os.Stderr, logLevel)
+	//the overlay is only drawn in the debug mode
+	logger.AddSink("display", dw, DebugLevel)
+	book.Warn = func(msg string, kv ...interface{}) {
+		logger.Warn(msg, fields(kv)...)
+	}
+}
+
+// fields pairs the keys and the values.
+func fields(kv []interface{}) []Field {
+	var rtn []Field
+	for i := 0; i+1 < len(kv); i += 2 {
+		rtn = append(rtn, F(fmt.Sprint(kv[i]), kv[i+1]))
+	}
+	return rtn
+}
+
+func NewLogger() *Logger {
+	var l Logger
+	l.sinks = make(map[string]*logSink)
+	return &l
+}
+
+func (l *Logger) AddSink(name string, w io.Writer, level Level) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.sinks[name] = &logSink{w, level}
+}
+
+func (l *Logger) RemoveSink(name string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	delete(l.sinks, name)
+}
+
+func (l *Logger) SetLevel(name string, level Level) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if s, ok := l.sinks[name]; ok {
+		s.level = level
+	}
+}
+
+func (l *Logger) Debug(msg string, fields ...Field) {
+	l.log(DebugLevel, msg, fields)
+}
+
+func (l *Logger) Info(msg string, fields ...Field) {
+	l.log(InfoLevel, msg, fields)
+}
+
+func (l *Logger) Warn(msg string, fields ...Field) {
+	l.log(WarnLevel, msg, fields)
+}
+
+func (l *Logger) Error(msg string, fields ...Field) {
+	l.log(ErrorLevel, msg, fields)
+}
+
+func (l *Logger) log(level Level, msg string, fields []Field) {
+
+	var b strings.Builder
+	b.WriteString(time.Now().Format(LogTimeFormat))
+	fmt.Fprintf(&b, " %-5s %s", level, msg)
+	for _, f := range fields {
+		b.WriteString(" ")
+		b.WriteString(f.Key)
+		b.WriteString("=")
+		b.WriteString(formatValue(f.Value))
+	}
+	b.WriteString("\n")
+	line := []byte(b.String())
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	for _, s := range l.sinks {
+		if level < s.level {
+			continue
+		}
+		//the failed sink can not be logged
+		s.w.Write(line)
+	}
+}
+
+// formatValue is one line, it is quoted when it has the spaces.
+func formatValue(v interface{}) string {
+	s := fmt.Sprintf("%v", v)
+	if strings.ContainsAny(s, " \t\n\"=") || s == "" {
+		return strconv.Quote(s)
+	}
+	return s
 }
 
-func setDebugDisplay(img *ebiten.Image) {
-	dw.Draw(img)
+func SetLogLevel(level Level) {
+	logLevel = level
+	logger.SetLevel("stderr", level)
+	logger.SetLevel("file", level)
 }
 
+// OpenLogFile adds the rotating file in the state directory to the sinks.
+func OpenLogFile() (io.Closer, error) {
+	dir := config.StateDir()
+	err := os.MkdirAll(dir, 0777)
+	if err != nil {
+		return nil, xerrors.Errorf("os.MkdirAll() error: %w", err)
+	}
+	w, err := NewRotateWriter(filepath.Join(dir, LogFileName), LogFileMaxSize, LogFileBackups)
+	if err != nil {
+		return nil, xerrors.Errorf("NewRotateWriter() error: %w", err)
+	}
+	logger.AddSink("file", w, logLevel)
+	return w, nil
+}
+
+// RotateWriter renames the file to name.1, name.2... when it is over the size.
+type RotateWriter struct {
+	mutex   sync.Mutex
+	name    string
+	max     int64
+	backups int
+
+	fp   *os.File
+	size int64
+}
+
+func NewRotateWriter(name string, max int64, backups int) (*RotateWriter, error) {
+	var w RotateWriter
+	w.name = name
+	w.max = max
+	w.backups = backups
+	err := w.open()
+	if err != nil {
+		return nil, xerrors.Errorf("open() error: %w", err)
+	}
+	return &w, nil
+}
+
+func (w *RotateWriter) open() error {
+	fp, err := os.OpenFile(w.name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		return xerrors.Errorf("os.OpenFile() error: %w", err)
+	}
+	info, err := fp.Stat()
+	if err != nil {
+		fp.Close()
+		return xerrors.Errorf("Stat() error: %w", err)
+	}
+	w.fp = fp
+	w.size = info.Size()
+	return nil
+}
+
+func (w *RotateWriter) rotate() error {
+
+	err := w.fp.Close()
+	if err != nil {
+		return xerrors.Errorf("Close() error: %w", err)
+	}
+
+	for idx := w.backups - 1; idx > 0; idx-- {
+		os.Rename(fmt.Sprintf("%s.%d", w.name, idx), fmt.Sprintf("%s.%d", w.name, idx+1))
+	}
+	if w.backups > 0 {
+		err = os.Rename(w.name, w.name+".1")
+	} else {
+		err = os.Remove(w.name)
+	}
+	if err != nil {
+		return xerrors.Errorf("rename error: %w", err)
+	}
+	return w.open()
+}
+
+func (w *RotateWriter) Write(buf []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.fp == nil {
+		return 0, xerrors.Errorf("closed log file")
+	}
+
+	if w.size+int64(len(buf)) > w.max && w.size > 0 {
+		err := w.rotate()
+		if err != nil {
+			return 0, xerrors.Errorf("rotate() error: %w", err)
+		}
+	}
+
+	n, err := w.fp.Write(buf)
+	w.size += int64(n)
+	if err != nil {
+		return n, xerrors.Errorf("Write() error: %w", err)
+	}
+	return n, nil
+}
+
+func (w *RotateWriter) Close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.fp == nil {
+		return nil
+	}
+	err := w.fp.Close()
+	w.fp = nil
+	if err != nil {
+		return xerrors.Errorf("Close() error: %w", err)
+	}
+	return nil
+}
+
+// DisplayWriter is the sink of the debug overlay.
 type DisplayWriter struct {
 	Display bool
-	lines   []string
 	img     *ebiten.Image
-	builder *strings.Builder
 
-	fontHeight int
+	mutex   sync.Mutex
+	builder *strings.Builder
 }
 
 func NewDisplayWriter() *DisplayWriter {
 	var dw DisplayWriter
-	dw.Display = true
-
-	dw.fontHeight = defaultFont.Metrics().XHeight.Ceil()
+	dw.Display = false
 
 	var builder strings.Builder
 	dw.builder = &builder
@@ -45,13 +319,16 @@ func NewDisplayWriter() *DisplayWriter {
 
 func (w *DisplayWriter) Write(buf []byte) (int, error) {
 
+	if w == nil {
+		return 0, xerrors.Errorf("Writer or Writer image is nil")
+	}
+
 	if !w.Display {
 		return len(buf), nil
 	}
 
-	if w == nil {
-		return 0, xerrors.Errorf("Writer or Writer image is nil")
-	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
 
 	//現在表示できる部分を表示
 	return w.builder.Write(buf)
@@ -64,42 +341,37 @@ const (
 func (w *DisplayWriter) Draw(img *ebiten.Image) error {
 
 	b := img.Bounds()
-	if dw.img == nil || b.Dy() != dw.img.Bounds().Dy() {
-		dw.img = ebiten.NewImage(b.Dx(), b.Dy())
+	if w.img == nil || b.Dy() != w.img.Bounds().Dy() {
+		w.img = ebiten.NewImage(b.Dx(), b.Dy())
 	}
 
-	dw.img.Clear()
-	dw.img.Fill(color.RGBA{0, 0, 0, 30})
+	w.img.Clear()
+	w.img.Fill(theme.DebugBackground)
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
 
-	buf := w.builder.String()
+	buf := strings.TrimSuffix(w.builder.String(), "\n")
 	lines := strings.Split(buf, "\n")
 
 	h := b.Dy()
-	dm := w.fontHeight + DisplayWriterMargin
+	dm := defaultFont.Metrics().XHeight.Ceil() + dp(DisplayWriterMargin)
 
-	startY := 0
 	startIdx := 0
 	for leng := len(lines); (leng * dm) > h; leng-- {
 		startIdx++
 	}
-
-	var writeLine []string
-	for idx := startIdx; idx < len(lines); idx++ {
-		line := lines[idx]
-		writeLine = append(writeLine, line)
-	}
+	writeLine := lines[startIdx:]
 
 	var builder strings.Builder
 	for idx, txt := range writeLine {
-		dy := (dm)*(idx+1) + startY
-		text.Draw(w.img, txt, defaultFont, 10, dy, color.RGBA{23, 200, 0, 255})
+		dy := dm * (idx + 1)
+		text.Draw(w.img, txt, defaultFont, dp(10), dy, theme.DebugText)
 		builder.WriteString(txt)
-		if idx+1 != len(writeLine) {
-			builder.WriteString("\n")
-		}
+		builder.WriteString("\n")
 	}
 
-	dw.builder = &builder
+	w.builder = &builder
 
 	img.DrawImage(w.img, nil)
 	return nil
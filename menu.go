@@ -1,8 +1,6 @@
 package wtv
 
 import (
-	"image/color"
-
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"golang.org/x/xerrors"
@@ -24,14 +22,14 @@ func (d Direction) Odds() int {
 	return 1
 }
 
+// Menu is the Component that comes out from the side of the screen.
+// The children are in the coordinates of the menu.
 type Menu struct {
 	direction Direction
 	state     MenuState
 	area      int
 	limit     int
-
-	relativeX int
-	relativeY int
+	hover     bool
 
 	move           int
 	areaMovement   int
@@ -40,6 +38,12 @@ type Menu struct {
 	x int
 	y int
 
+	screenWidth  int
+	screenHeight int
+
+	width  int
+	height int
+
 	img *ebiten.Image
 
 	*Components
@@ -84,109 +88,141 @@ func NewMenu(d Direction, area, limit int) *Menu {
 	m.areaMovement = defaultAreaMovement
 	m.activeMovement = defaultActiveMovement
 	m.img = nil
-	//TODO Menu implement Component
-	m.Components = NewComponents()
+	m.Components = NewComponents(&m)
 
 	return &m
 }
 
-func (m *Menu) updatePosition(w, h int) bool {
+// Set receives the screen size, the menu size is decided by the direction.
+func (m *Menu) Set(w, h int) {
+
+	m.screenWidth = w
+	m.screenHeight = h
+
+	dw := w
+	dh := h
+	switch m.direction {
+	case N, S:
+		dh = m.limit
+	case E, W:
+		dw = m.limit
+	}
+
+	if dw != m.width || dh != m.height {
+		m.width, m.height = dw, dh
+		m.Components.Set(dw, dh)
+	}
+}
+
+func (m *Menu) Size() (int, int) {
+	return m.width, m.height
+}
+
+// Move is nothing, the position is decided by the direction and the state.
+func (m *Menu) Move(x, y int) error {
+	return nil
+}
+
+func (m *Menu) Point() (float64, float64) {
+	return float64(m.x), float64(m.y)
+}
+
+// In is the area that opens the menu.
+func (m *Menu) In(x, y int) bool {
 
-	m.relativeX = -1
-	m.relativeY = -1
-	nowX, nowY := ebiten.CursorPosition()
+	w := m.screenWidth
+	h := m.screenHeight
+	if x <= 0 || x >= w || y <= 0 || y >= h {
+		return false
+	}
 
-	rtn := false
 	area := m.area
 	if m.area == 0 || m.state == MenuActiveState || m.state == MenuHideState {
 		area = m.limit
 	}
 
-	dw := w
-	dh := h
+	switch m.direction {
+	case N:
+		return y <= area
+	case S:
+		return y >= h-area
+	case W:
+		return x <= area
+	case E:
+		return x >= w-area
+	}
+	return false
+}
+
+// Children are the targets of the events while the menu is active.
+func (m *Menu) Children() []Component {
+	if m.state != MenuActiveState {
+		return nil
+	}
+	return m.Components.Children()
+}
+
+// Modal keeps the events in the active menu.
+func (m *Menu) Modal() bool {
+	return m.state == MenuActiveState
+}
+
+func (m *Menu) HandleEvent(e *Event) error {
+	switch e.Type {
+	case MouseEnterEvent:
+		m.hover = true
+	case MouseLeaveEvent:
+		m.hover = false
+	case MouseDownEvent:
+		if m.state == MenuAreaState {
+			m.state = MenuActiveState
+			e.Consume()
+		}
+	}
+	return nil
+}
+
+func (m *Menu) updatePosition() {
+
+	w := m.screenWidth
+	h := m.screenHeight
 
 	switch m.direction {
 	case N:
 		m.x = 0
 		m.y = m.limit * -1
-		dh = m.limit
-		if nowY <= area {
-			rtn = true
-			if m.state == MenuActiveState {
-				m.relativeX = nowX
-				m.relativeY = nowY
-			}
-		}
 	case S:
 		m.x = 0
 		m.y = h
-		dh = m.limit
-		if nowY >= h-area {
-			rtn = true
-			if m.state == MenuActiveState {
-				m.relativeX = nowX
-				m.relativeY = m.limit - (h - nowY)
-			}
-		}
 	case W:
 		m.x = m.limit * -1
 		m.y = 0
-		dw = m.limit
-		if nowX <= area {
-			rtn = true
-			if m.state == MenuActiveState {
-				m.relativeX = nowX
-				m.relativeY = nowY
-			}
-		}
 	case E:
 		m.x = w
 		m.y = 0
-		dw = m.limit
-		if nowX >= w-area {
-			rtn = true
-			if m.state == MenuActiveState {
-				m.relativeX = w - nowX
-				m.relativeY = nowY
-			}
-		}
 	}
 
-	m.img = ebiten.NewImage(dw, dh)
-	m.img.Fill(color.RGBA{0, 0, 0, 255})
+	if m.width <= 0 || m.height <= 0 {
+		return
+	}
+
+	m.img = ebiten.NewImage(m.width, m.height)
+	m.img.Fill(theme.MenuBackground)
 
 	if m.area != 0 &&
 		(m.state == MenuAreaState || (m.state == MenuActiveState && m.move != m.limit)) {
 		center := w / 2
-		leng := 80
-		uy := m.limit - m.area + 5
-		dy := m.limit - 5
+		leng := dp(80)
+		uy := m.limit - m.area + dp(5)
+		dy := m.limit - dp(5)
 		vertecies := []ebiten.Vertex{
 			newVertex(center-(leng/2), uy), newVertex(center+(leng/2), uy), newVertex(center, dy),
 		}
 		indices := []uint16{0, 1, 2}
 		white := ebiten.NewImage(10, 10)
-		white.Fill(color.RGBA{200, 200, 200, 255})
+		white.Fill(theme.MenuArrow)
 		m.img.DrawTriangles(vertecies, indices, white, nil)
 	}
-
-	if !rtn {
-		if m.state == MenuActiveState {
-			if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
-				m.state = MenuHideState
-			} else if m.area == 0 {
-				m.state = MenuHideState
-			}
-		}
-	}
-
-	if nowX <= 0 || nowX >= w {
-		return false
-	}
-	if nowY <= 0 || nowY >= h {
-		return false
-	}
-	return rtn
 }
 
 func newVertex(x, y int) ebiten.Vertex {
@@ -196,19 +232,28 @@ func newVertex(x, y int) ebiten.Vertex {
 	}
 }
 
-func (m *Menu) Update(w, h int) error {
+// Update moves the menu, x and y are the cursor in the parent.
+func (m *Menu) Update(x, y int) error {
 
-	ebiten.SetCursorShape(ebiten.CursorShapeDefault)
+	m.updatePosition()
 
-	area := m.updatePosition(w, h)
+	area := m.hover
 	if !area {
-		if m.state == MenuAreaState {
+		switch m.state {
+		case MenuAreaState:
 			m.state = MenuHideState
+		case MenuActiveState:
+			if m.area == 0 || inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+				m.state = MenuHideState
+			}
 		}
 	}
 
-	//TODO event作り直しが必要
-	err := m.Components.Update(m.relativeX, m.relativeY)
+	rx, ry := -1, -1
+	if m.state == MenuActiveState {
+		rx, ry = x-m.x, y-m.y
+	}
+	err := m.Components.Update(rx, ry)
 	if err != nil {
 		return xerrors.Errorf("Components.Update() error: %w", err)
 	}
@@ -227,11 +272,6 @@ func (m *Menu) Update(w, h int) error {
 		if m.move > m.area {
 			m.move = m.area
 		}
-		//TODO 中央？
-		if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
-			m.state = MenuActiveState
-		}
-
 	case MenuHideState:
 		m.move -= m.activeMovement
 		if m.move <= 0 {
@@ -255,11 +295,24 @@ func (m *Menu) Update(w, h int) error {
 	return nil
 }
 
+// Toggle opens or closes the menu without the mouse.
+func (m *Menu) Toggle() {
+	if m.state == MenuActiveState {
+		m.state = MenuHideState
+		m.Components.Blur()
+		return
+	}
+	m.state = MenuActiveState
+}
+
 func (m *Menu) Active() bool {
 	return m.state != MenuOFFState
 }
 
 func (m *Menu) Draw(img *ebiten.Image) error {
+	if m.img == nil {
+		return nil
+	}
 	var err error
 	switch m.state {
 	case MenuAreaState:
@@ -278,7 +331,7 @@ func (m *Menu) drawArea(img *ebiten.Image) error {
 	op := &ebiten.DrawImageOptions{}
 
 	op.GeoM.Translate(float64(m.x), float64(m.y))
-	op.ColorM.Scale(1, 1, 1, 0.5)
+	op.ColorM.Scale(1, 1, 1, theme.AreaOpacity)
 
 	img.DrawImage(m.img, op)
 
@@ -294,7 +347,7 @@ func (m *Menu) drawActive(img *ebiten.Image) error {
 
 	op := &ebiten.DrawImageOptions{}
 	op.GeoM.Translate(float64(m.x), float64(m.y))
-	op.ColorM.Scale(1, 1, 1, 0.9)
+	op.ColorM.Scale(1, 1, 1, theme.ActiveOpacity)
 	img.DrawImage(m.img, op)
 	return nil
 }
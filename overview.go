@@ -0,0 +1,266 @@
+package wtv
+
+import (
+	"fmt"
+	"image"
+	"sync"
+	"wtv/book"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/xerrors"
+)
+
+const (
+	OverviewCellWidth  = 120
+	OverviewCellHeight = 160
+	OverviewCellMargin = 10
+	OverviewLabelSpace = 20
+)
+
+type overviewThumb struct {
+	index int
+	img   image.Image
+}
+
+// Overview is a full screen grid of the page thumbnails.
+type Overview struct {
+	scenes  *SceneManager
+	book    *book.Book
+	current int
+	scroll  int
+
+	width  int
+	height int
+
+	cache    *ThumbnailCache
+	textures map[int]*ebiten.Image
+	selected func(int) error
+
+	mutex      sync.Mutex
+	generation int
+	thumbs     []overviewThumb
+}
+
+func NewOverview(selected func(int) error) *Overview {
+	var o Overview
+	o.cache = NewThumbnailCache(dp(OverviewCellWidth), dp(OverviewCellHeight)-dp(OverviewLabelSpace))
+	o.textures = make(map[int]*ebiten.Image)
+	o.selected = selected
+	return &o
+}
+
+func (o *Overview) Show(b *book.Book, current, w, h int) {
+
+	if b == nil {
+		return
+	}
+
+	o.width, o.height = w, h
+
+	o.mutex.Lock()
+	o.generation++
+	gen := o.generation
+	o.thumbs = nil
+	if o.book != b {
+		o.cache.Clear()
+		o.textures = make(map[int]*ebiten.Image)
+	}
+	o.mutex.Unlock()
+
+	o.book = b
+	o.current = current
+
+	//current page is at the center
+	row := current / o.columns()
+	o.scroll = row*(dp(OverviewCellHeight)+dp(OverviewCellMargin)) - h/2 + dp(OverviewCellHeight)/2
+	o.clamp()
+
+	go o.load(gen, b, current)
+}
+
+func (o *Overview) Enter(m *SceneManager) error {
+	o.scenes = m
+	return nil
+}
+
+func (o *Overview) Leave() error {
+	o.mutex.Lock()
+	o.generation++
+	o.thumbs = nil
+	o.mutex.Unlock()
+	return nil
+}
+
+func (o *Overview) Close() error {
+	if o.scenes == nil {
+		return nil
+	}
+	return o.scenes.Remove(o)
+}
+
+// load makes the thumbnails from the current page to the outside.
+func (o *Overview) load(gen int, b *book.Book, current int) {
+
+	for d := 0; d < b.Page(); d++ {
+		for _, idx := range []int{current + d, current - d - 1} {
+			if idx < 0 || idx >= b.Page() {
+				continue
+			}
+
+			o.mutex.Lock()
+			stale := gen != o.generation
+			_, done := o.textures[idx]
+			o.mutex.Unlock()
+			if stale {
+				return
+			}
+			if done {
+				continue
+			}
+
+			img, err := o.cache.Get(b.File(idx), book.Load)
+			if err != nil {
+				logger.Warn("thumbnail error", F("file", b.File(idx)), F("err", err))
+				continue
+			}
+
+			o.mutex.Lock()
+			if gen == o.generation {
+				o.thumbs = append(o.thumbs, overviewThumb{idx, img})
+			}
+			o.mutex.Unlock()
+		}
+	}
+}
+
+func (o *Overview) columns() int {
+	col := (o.width - dp(OverviewCellMargin)) / (dp(OverviewCellWidth) + dp(OverviewCellMargin))
+	if col < 1 {
+		col = 1
+	}
+	return col
+}
+
+func (o *Overview) clamp() {
+	col := o.columns()
+	rows := (o.book.Page() + col - 1) / col
+	max := rows*(dp(OverviewCellHeight)+dp(OverviewCellMargin)) + dp(OverviewCellMargin) - o.height
+	if o.scroll > max {
+		o.scroll = max
+	}
+	if o.scroll < 0 {
+		o.scroll = 0
+	}
+}
+
+func (o *Overview) cell(idx int) (int, int) {
+	col := o.columns()
+	x := dp(OverviewCellMargin) + (idx%col)*(dp(OverviewCellWidth)+dp(OverviewCellMargin))
+	y := dp(OverviewCellMargin) + (idx/col)*(dp(OverviewCellHeight)+dp(OverviewCellMargin)) - o.scroll
+	return x, y
+}
+
+// index returns the page index at the screen point or -1.
+func (o *Overview) index(x, y int) int {
+	col := o.columns()
+	cw := dp(OverviewCellWidth) + dp(OverviewCellMargin)
+	ch := dp(OverviewCellHeight) + dp(OverviewCellMargin)
+
+	x -= dp(OverviewCellMargin)
+	y += o.scroll - dp(OverviewCellMargin)
+	if x < 0 || y < 0 || x%cw >= dp(OverviewCellWidth) || y%ch >= dp(OverviewCellHeight) {
+		return -1
+	}
+	c := x / cw
+	if c >= col {
+		return -1
+	}
+	idx := (y/ch)*col + c
+	if idx >= o.book.Page() {
+		return -1
+	}
+	return idx
+}
+
+func (o *Overview) Update(w, h int) error {
+
+	o.width, o.height = w, h
+
+	o.mutex.Lock()
+	thumbs := o.thumbs
+	o.thumbs = nil
+	for _, th := range thumbs {
+		o.textures[th.index] = ebiten.NewImageFromImage(th.img)
+	}
+	o.mutex.Unlock()
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		return o.Close()
+	}
+
+	_, dy := ebiten.Wheel()
+	o.scroll -= int(dy * float64(dp(40)))
+	o.clamp()
+
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		idx := o.index(ebiten.CursorPosition())
+		if idx != -1 {
+			err := o.Close()
+			if err != nil {
+				return xerrors.Errorf("Close() error: %w", err)
+			}
+			err = o.selected(idx)
+			if err != nil {
+				return xerrors.Errorf("selected() error: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+func (o *Overview) Draw(screen *ebiten.Image) error {
+
+	screen.Fill(theme.Background)
+
+	for idx := 0; idx < o.book.Page(); idx++ {
+
+		x, y := o.cell(idx)
+		if y+dp(OverviewCellHeight) < 0 || y > o.height {
+			continue
+		}
+
+		if idx == o.current {
+			m := dpf(4)
+			ebitenutil.DrawRect(screen, float64(x)-m, float64(y)-m,
+				float64(dp(OverviewCellWidth))+m*2, float64(dp(OverviewCellHeight))+m*2, theme.Active)
+		}
+		ebitenutil.DrawRect(screen, float64(x), float64(y),
+			float64(dp(OverviewCellWidth)), float64(dp(OverviewCellHeight)), theme.Item)
+
+		o.mutex.Lock()
+		tex, ok := o.textures[idx]
+		o.mutex.Unlock()
+		if ok {
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Translate(float64(x), float64(y))
+			screen.DrawImage(tex, op)
+		}
+
+		text.Draw(screen, fmt.Sprintf("%d", idx+1), fontFace(FontSmallScale),
+			x+dp(4), y+dp(OverviewCellHeight)-dp(4), theme.Text)
+	}
+	return nil
+}
+
+func (o *Overview) Inspect() ([]Component, []string) {
+	o.mutex.Lock()
+	loading := len(o.thumbs)
+	o.mutex.Unlock()
+	return nil, []string{
+		fmt.Sprintf("current %d scroll %d", o.current, o.scroll),
+		fmt.Sprintf("textures %d loaded %d", len(o.textures), loading),
+	}
+}
@@ -1,12 +1,15 @@
 package wtv
 
 import (
-	"errors"
-	"log"
+	"fmt"
+	"path/filepath"
+	"sync/atomic"
+	"wtv/book"
 	"wtv/config"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/sqweek/dialog"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
 	"golang.org/x/xerrors"
 )
 
@@ -16,13 +19,39 @@ type Player struct {
 
 	viewRedraw bool
 
-	viewer *Viewer
+	viewer  *Viewer
+	gamepad *Gamepad
 
 	topMenu      *Menu
 	scrollMenu   *ScrollMenu
 	controllMenu *Menu
+	slider       *Slider
+	preview      *SliderPreview
+
+	ui     *Components
+	events *EventDispatcher
+	//disabled until a book is opened
+	bookButtons []*ButtonObserver
+
+	scenes   *SceneManager
+	browser  *Browser
+	overview *Overview
+	problems *ProblemReport
+	help     *Help
+	settings *Settings
+
+	sortGroup *RadioGroup
+	orderBtn  *CircleButton
+
+	bookKey string
+	ticks   int
+
+	//1 while the book is written in the background
+	exporting int32
 }
 
+const PositionSaveInterval = 60 * 5
+
 func NewPlayer() *Player {
 
 	var p Player
@@ -30,41 +59,44 @@ func NewPlayer() *Player {
 	p.height = 0
 	p.viewRedraw = true
 	p.viewer = NewViewer()
+	p.gamepad = NewGamepad(nil, config.Get().Gamepad)
 
-	p.topMenu = NewMenu(N, 30, 110)
+	p.topMenu = NewMenu(N, dp(30), dp(110))
 
-	sortBtn1 := NewTextButton("Numeric", 100, 10, 90, 30)
-	sortBtn2 := NewTextButton("Alphanumeric", 200, 10, 90, 30)
-	sortBtn3 := NewTextButton("Modtime", 300, 10, 90, 30)
-	sortBtn1.Click(func() error {
-		err := changeSortConfig(config.NumericSort)
-		if err != nil {
-			return xerrors.Errorf("changeSortConfig() numeric error: %w", err)
-		}
-		p.viewRedraw = true
-		p.viewer.reset()
-		return nil
-	})
-	sortBtn2.Click(func() error {
-		err := changeSortConfig(config.AlphamericSort)
-		if err != nil {
-			return xerrors.Errorf("changeSortConfig() numeric error: %w", err)
+	conf := config.Get()
+
+	sortBtn1 := NewTextButton("Numeric", 0, 0, dp(90), dp(30))
+	sortBtn2 := NewTextButton("Alphanumeric", 0, 0, dp(90), dp(30))
+	sortBtn3 := NewTextButton("Modtime", 0, 0, dp(90), dp(30))
+
+	orderBtn := NewCircleButton(0, 0, dp(32))
+	orderBtn.PasteImage(ResSwap)
+
+	sortGroup := NewRadioGroup(func(v int) error {
+		t := config.SortType(v)
+		if orderBtn.IsActive() {
+			t = t.Reverse()
 		}
-		p.viewRedraw = true
-		p.viewer.reset()
-		return nil
+		return p.changeSort(t)
 	})
-	sortBtn3.Click(func() error {
-		err := changeSortConfig(config.ModTimeSort)
-		if err != nil {
-			return xerrors.Errorf("changeSortConfig() numeric error: %w", err)
+	sortGroup.Add(sortBtn1, int(config.NumericSort))
+	sortGroup.Add(sortBtn2, int(config.AlphamericSort))
+	sortGroup.Add(sortBtn3, int(config.ModTimeSort))
+
+	p.sortGroup = sortGroup
+	p.orderBtn = orderBtn
+	p.setSortButtons(conf.Sort)
+
+	//active is descending
+	orderBtn.Toggle(func(desc bool) error {
+		t := config.Get().Sort.Kind()
+		if desc {
+			t = t.Reverse()
 		}
-		p.viewRedraw = true
-		p.viewer.reset()
-		return nil
+		return p.changeSort(t)
 	})
 
-	autoBtn := NewCircleButton(500, 45, 32)
+	autoBtn := NewCircleButton(0, 0, dp(32))
 	autoBtn.Click(func() error {
 		if p.viewer.playMode == AutoPlayMode {
 			p.viewer.playMode = NormalPlayMode
@@ -73,81 +105,355 @@ func NewPlayer() *Player {
 			p.viewer.playMode = AutoPlayMode
 			autoBtn.PasteImage(ResPause)
 		}
+		logger.Debug("play mode", F("auto", p.viewer.playMode == AutoPlayMode), F("offset", p.viewer.offset))
 		p.topMenu.state = MenuHideState
 		return nil
 	})
 	autoBtn.PasteImage(ResPlay)
 
-	btn := NewCircleButton(50, 45, 32)
-	btn.PasteImage(ResFolder)
+	pagesBtn := NewTextButton("Pages", 0, 0, dp(90), dp(30))
+	pagesBtn.Click(func() error {
+		p.topMenu.state = MenuHideState
+		return p.showOverview()
+	})
 
-	slider := NewSlider()
+	exportBtn := NewTextButton("Export", 0, 0, dp(90), dp(30))
+	exportBtn.Click(func() error {
+		p.topMenu.state = MenuHideState
+		p.export()
+		return nil
+	})
 
-	btn.Click(func() error {
+	p.bookButtons = []*ButtonObserver{autoBtn.Observer(), pagesBtn.Observer(), exportBtn.Observer()}
+	for _, bo := range p.bookButtons {
+		bo.SetDisabled(true)
+	}
 
-		conf := config.Get()
+	btn := NewCircleButton(0, 0, dp(32))
+	btn.PasteImage(ResFolder)
 
-		t := "Load Webtoon Directory"
-		dir := conf.Directory
+	slider := NewSlider()
+	p.slider = slider
+	p.preview = NewSliderPreview()
+	p.browser = NewBrowser(p.openBook)
+	p.overview = NewOverview(func(idx int) error {
+		p.viewer.Jump(idx, 0)
+		return nil
+	})
+	p.problems = NewProblemReport(func(idx int) error {
+		p.viewer.Jump(idx, 0)
+		return nil
+	})
 
-		builder := dialog.Directory().Title(t)
-		builder.StartDir = dir
-		dir, err := builder.Browse()
-		if err != nil {
-			if errors.Is(err, dialog.ErrCancelled) {
-				return nil
-			}
-			return xerrors.Errorf("dialog.Directory() error: %w", err)
-		}
+	p.help = NewHelp()
+	p.settings = NewSettings(p.changeSort, p.applyConfig)
 
-		p.viewer.SetBook(dir)
+	settingsBtn := NewTextButton("Settings", 0, 0, dp(90), dp(30))
+	settingsBtn.Click(func() error {
 		p.topMenu.state = MenuHideState
-		p.viewRedraw = true
-
-		slider.SetMax(len(p.viewer.book.files))
-		slider.SetValue(1)
-
-		conf.Directory = dir
-		err = config.Save()
-		if err != nil {
-			return xerrors.Errorf("config.Save() error: %w", err)
-		}
-		return nil
+		return p.scenes.Push(p.settings)
 	})
 
-	slider.Changed(func(v int) error {
-
-		p.viewer.reset()
-		p.viewer.index = v - 1
-		p.viewer.pos = 0
-		p.viewRedraw = true
+	btn.Click(func() error {
+		p.topMenu.state = MenuHideState
+		return p.showBrowser()
+	})
 
+	slider.Changed(func(v float64) error {
+		p.viewer.SetOffset(int(v * float64(p.viewer.MaxOffset())))
 		return nil
 	})
 
+	spacer := NewSpacer(0, 0)
+
 	p.topMenu.Add(btn)
 	p.topMenu.Add(sortBtn1)
 	p.topMenu.Add(sortBtn2)
 	p.topMenu.Add(sortBtn3)
+	p.topMenu.Add(orderBtn)
+	p.topMenu.Add(spacer)
+	p.topMenu.Add(settingsBtn)
+	p.topMenu.Add(exportBtn)
+	p.topMenu.Add(pagesBtn)
 	p.topMenu.Add(autoBtn)
 
+	topLayout := NewHBox()
+	topLayout.Padding = NewPadding(dp(10))
+	topLayout.Spacing = dp(10)
+	topLayout.Grow(spacer, 1)
+	p.topMenu.SetLayout(topLayout)
+
 	p.topMenu.state = MenuActiveState
 
-	m := NewMenu(E, 0, 100)
+	m := NewMenu(E, 0, dp(100))
 	p.scrollMenu = NewScrollMenu(m)
 
-	cm := NewMenu(S, 0, 80)
+	cm := NewMenu(S, 0, dp(80))
 
 	cm.Add(slider)
+
+	cmLayout := NewHBox()
+	cmLayout.Padding = Padding{Left: dp(SliderMargin), Right: dp(SliderMargin)}
+	cmLayout.Align = AlignStart
+	cmLayout.Grow(slider, 1)
+	cm.SetLayout(cmLayout)
+
 	p.controllMenu = cm
 
+	//the last is the top
+	p.ui = NewComponents(nil)
+	p.ui.Add(p.scrollMenu)
+	p.ui.Add(p.controllMenu)
+	p.ui.Add(p.topMenu)
+	p.events = NewEventDispatcher()
+
 	return &p
 }
 
+// Enter starts with the library when the player is the root scene.
+func (p *Player) Enter(m *SceneManager) error {
+	p.scenes = m
+	return p.showBrowser()
+}
+
+func (p *Player) Leave() error {
+	return p.savePosition()
+}
+
+func (p *Player) showBrowser() error {
+	p.browser.Show(StartDirectory())
+	return p.scenes.Push(p.browser)
+}
+
+func (p *Player) openBook(dir string) error {
+
+	err := p.savePosition()
+	if err != nil {
+		return xerrors.Errorf("savePosition() error: %w", err)
+	}
+
+	err = p.viewer.SetBook(dir)
+	if err != nil {
+		return xerrors.Errorf("SetBook() error: %w", err)
+	}
+	p.viewRedraw = true
+	p.bookKey = dir
+	for _, bo := range p.bookButtons {
+		bo.SetDisabled(false)
+	}
+
+	conf := config.Get()
+	if r, ok := conf.Position(dir); ok {
+		p.viewer.SetOffset(int(r * float64(p.viewer.width)))
+	}
+
+	p.preview = NewSliderPreview()
+	p.updateSlider()
+	p.slider.SetTicks(p.chapterTicks())
+
+	conf.Directory = dir
+	conf.AddRecent(dir)
+	err = config.Save()
+	if err != nil {
+		return xerrors.Errorf("config.Save() error: %w", err)
+	}
+	return nil
+}
+
+// savePosition records the reading position for the resume.
+func (p *Player) savePosition() error {
+
+	if !p.isView() || p.bookKey == "" || p.viewer.width == 0 {
+		return nil
+	}
+
+	conf := config.Get()
+	r := float64(p.viewer.Offset()) / float64(p.viewer.width)
+	if v, ok := conf.Position(p.bookKey); ok && v == r {
+		return nil
+	}
+
+	conf.SetPosition(p.bookKey, r)
+	err := config.Save()
+	if err != nil {
+		return xerrors.Errorf("config.Save() error: %w", err)
+	}
+	return nil
+}
+
+func (p *Player) updateSlider() {
+	if !p.isView() {
+		return
+	}
+	v := p.viewer
+	max := v.MaxOffset()
+	if max > 0 {
+		p.slider.SetValue(float64(v.Offset()) / float64(max))
+	}
+	p.slider.SetLabel(fmt.Sprintf("%d/%d", v.index+1, v.book.Page()))
+}
+
+// chapterTicks returns the chapter starts in the slider value.
+func (p *Player) chapterTicks() []float64 {
+	v := p.viewer
+	max := v.MaxOffset()
+	if max <= 0 {
+		return nil
+	}
+	var rtn []float64
+	doc := v.book.Document()
+	for _, c := range v.book.Chapters() {
+		if c == 0 {
+			continue
+		}
+		rtn = append(rtn, float64(doc.Offset(c, 0, v.width))/float64(max))
+	}
+	return rtn
+}
+
+func (p *Player) drawPreview(screen *ebiten.Image) {
+
+	if !p.isView() || !p.controllMenu.Active() {
+		return
+	}
+	val, ok := p.slider.Preview()
+	if !ok {
+		return
+	}
+
+	v := p.viewer
+	idx, _ := v.book.Document().Locate(int(val*float64(v.MaxOffset())), v.width)
+	tex := p.preview.Get(v.book.File(idx))
+	if tex == nil {
+		return
+	}
+
+	b := tex.Bounds()
+	x := p.slider.PreviewX(val) - b.Dx()/2
+	if x < 0 {
+		x = 0
+	}
+	if x+b.Dx() > p.width {
+		x = p.width - b.Dx()
+	}
+	y := p.height - p.controllMenu.limit - b.Dy() - dp(10)
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(x), float64(y))
+	screen.DrawImage(tex, op)
+	text.Draw(screen, fmt.Sprintf("%d", idx+1), defaultFont, x+dp(4), y+b.Dy()-dp(4), theme.Text)
+}
+
+func (p *Player) showOverview() error {
+	if !p.isView() {
+		return nil
+	}
+	p.overview.Show(p.viewer.book, p.viewer.index, p.width, p.height)
+	return p.scenes.Push(p.overview)
+}
+
+// export writes the open book in the current order next to it.
+// It runs in the background and the result is notified.
+func (p *Player) export() {
+
+	b := p.viewer.book
+	if b == nil || !atomic.CompareAndSwapInt32(&p.exporting, 0, 1) {
+		return
+	}
+
+	name := book.ExportPath(p.bookKey)
+	opts := book.ExportOptions{
+		Title:     book.ExportTitle(p.bookKey),
+		ComicInfo: config.Get().ExportComicInfo,
+	}
+	toasts.Notify(InfoLevel, "exporting "+filepath.Base(name))
+
+	go func() {
+		defer atomic.StoreInt32(&p.exporting, 0)
+		err := b.ExportCBZ(name, opts, nil)
+		if err != nil {
+			logger.Error("export error", F("file", name), F("err", err))
+			toasts.Notify(ErrorLevel, "export failed: "+book.CauseOf(err).Error())
+			return
+		}
+		logger.Info("exported", F("file", name), F("pages", b.Page()))
+		toasts.Notify(InfoLevel, "exported to "+name)
+	}()
+}
+
+func (p *Player) showProblems() error {
+	if !p.isView() {
+		return nil
+	}
+	p.problems.Show(p.viewer.book)
+	return p.scenes.Push(p.problems)
+}
+
+// changeSort sorts the open book again and saves the setting.
+func (p *Player) changeSort(t config.SortType) error {
+
+	err := changeSortConfig(t)
+	if err != nil {
+		return xerrors.Errorf("changeSortConfig() error: %w", err)
+	}
+	p.setSortButtons(t)
+
+	if !p.isView() {
+		return nil
+	}
+	p.viewer.Sort(t)
+	p.scrollMenu.Reset()
+	p.slider.SetTicks(p.chapterTicks())
+	p.updateSlider()
+	return nil
+}
+
+// setSortButtons shows the sort in the top menu.
+// The none sort has no radio button and no order, the order button is disabled.
+func (p *Player) setSortButtons(t config.SortType) {
+	if t == config.DoNotSort {
+		p.sortGroup.Clear()
+		p.orderBtn.SetActive(false)
+		p.orderBtn.SetDisabled(true)
+		return
+	}
+	p.sortGroup.Set(int(t.Kind()))
+	//active is descending
+	p.orderBtn.SetActive(!t.Asc())
+	p.orderBtn.SetDisabled(false)
+}
+
+// applyConfig reflects the settings that are not read at each use.
+func (p *Player) applyConfig() error {
+	conf := config.Get()
+	p.gamepad.SetMapping(conf.Gamepad)
+	if conf.Theme != theme.Name || !fonts.Loaded(conf.Fonts) {
+		err := fonts.Load(conf.Fonts)
+		if err != nil {
+			return xerrors.Errorf("fonts.Load() error: %w", err)
+		}
+		t, err := LoadTheme(conf.Theme)
+		if err != nil {
+			return xerrors.Errorf("LoadTheme() error: %w", err)
+		}
+		err = SetTheme(t)
+		if err != nil {
+			return xerrors.Errorf("SetTheme() error: %w", err)
+		}
+	}
+	return nil
+}
+
 func changeSortConfig(t config.SortType) error {
 	conf := config.Get()
+	if conf.Sort == t {
+		return nil
+	}
 	conf.Sort = t
-	//Save is redraw
+	err := config.Save()
+	if err != nil {
+		return xerrors.Errorf("config.Save() error: %w", err)
+	}
 	return nil
 }
 
@@ -161,16 +467,21 @@ func (p *Player) Layout(w, h int) (int, int) {
 		p.width = w
 		p.height = h
 		p.viewRedraw = true
+		for _, c := range p.ui.Children() {
+			c.Set(w, h)
+		}
 	} else if p.viewRedraw {
 		if p.isView() {
 			p.viewer.Redraw(w, h)
 
 			conf := config.Get()
-			conf.Width = w
-			conf.Height = h
+			//the window size is not in the device pixels
+			conf.Width = fromDevice(w)
+			conf.Height = fromDevice(h)
 			err := config.Save()
 			if err != nil {
-				log.Println(err)
+				logger.Error("config save error", F("err", err))
+				toasts.Notify(ErrorLevel, "the window size could not be saved")
 			}
 		} else {
 			p.viewer.width = w
@@ -182,60 +493,70 @@ func (p *Player) Layout(w, h int) (int, int) {
 	return w, h
 }
 
-func (p *Player) Update() error {
+func (p *Player) Update(w, h int) error {
 
-	// TODO Updateが必要かどうか？
+	if inpututil.IsKeyJustPressed(ebiten.KeyG) {
+		return p.showOverview()
+	}
+	if inpututil.IsKeyJustPressed(ProblemsKey) {
+		return p.showProblems()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF1) {
+		return p.scenes.Push(p.help)
+	}
 
-	if !p.viewer.Dragging() {
-		if !p.topMenu.Active() && !p.controllMenu.Active() {
-
-			p.scrollMenu.Update(p.width, p.height)
-			idx := p.scrollMenu.selectedIndex
-			if idx != -1 {
-				p.viewer.reset()
-				p.viewer.index = idx
-				p.viewer.pos = p.scrollMenu.selectedPos
-				p.viewer.Redraw(p.width, p.height)
-				p.scrollMenu.selectedIndex = -1
-				p.scrollMenu.selectedPos = -1
-				p.scrollMenu.state = MenuHideState
-
-				//TODO Slider
-			}
+	p.ticks++
+	if p.ticks%PositionSaveInterval == 0 {
+		err := p.savePosition()
+		if err != nil {
+			logger.Error("position save error", F("err", err))
+			toasts.Notify(ErrorLevel, "the reading position could not be saved")
 		}
+	}
+
+	err := p.updateGamepad()
+	if err != nil {
+		return xerrors.Errorf("updateGamepad() error: %w", err)
+	}
+
+	if !p.viewer.Dragging() {
+
+		ebiten.SetCursorShape(ebiten.CursorShapeDefault)
+		p.updateSlider()
 
-		if !p.scrollMenu.Active() && !p.controllMenu.Active() {
-			p.topMenu.Update(p.width, p.height)
+		x, y := ebiten.CursorPosition()
+		err := p.events.Dispatch(p.ui.Children(), x, y)
+		if err != nil {
+			return xerrors.Errorf("Dispatch() error: %w", err)
+		}
+		err = p.ui.Update(x, y)
+		if err != nil {
+			return xerrors.Errorf("ui Update() error: %w", err)
 		}
 
-		if !p.scrollMenu.Active() && !p.topMenu.Active() {
-			for _, comp := range p.controllMenu.Components.children {
-				if v, ok := comp.(*Slider); ok {
-					v.SetValue(p.viewer.index + 1)
-				}
-			}
-			p.controllMenu.Update(p.width, p.height)
+		idx := p.scrollMenu.selectedIndex
+		if idx != -1 {
+			p.viewer.Jump(idx, p.scrollMenu.selectedPos)
+			p.scrollMenu.selectedIndex = -1
+			p.scrollMenu.selectedPos = -1
+			p.scrollMenu.state = MenuHideState
 		}
 	}
 
 	if p.scrollMenu.Active() {
-
-		book, idx := p.viewer.GetBook()
+		book, _ := p.viewer.GetBook()
 		if book != nil {
-			err := p.scrollMenu.Load(book, idx, p.viewer.width, p.viewer.pos)
+			err := p.scrollMenu.Load(book, p.viewer.Offset(), p.viewer.width, p.viewer.height)
 			if err != nil {
-				return xerrors.Errorf("ScrollMenu Load() error: %w")
+				logger.Warn("minimap load error", F("err", err))
 			}
 		}
-		return nil
 	} else {
 		p.scrollMenu.Reset()
 	}
 
-	if p.topMenu.Active() {
-		return nil
-	}
-	if p.scrollMenu.Active() {
+	//the menus use the mouse
+	if p.events.Captured() {
 		return nil
 	}
 
@@ -248,20 +569,80 @@ func (p *Player) Update() error {
 	return nil
 }
 
-func (p *Player) Draw(screen *ebiten.Image) {
+func (p *Player) Inspect() ([]Component, []string) {
+	v := p.viewer
+	lines := []string{
+		fmt.Sprintf("menu top %s scroll %s control %s", p.topMenu.state, p.scrollMenu.state, p.controllMenu.state),
+		fmt.Sprintf("hover %T", p.events.Hover()),
+	}
+	if v.book != nil {
+		lines = append(lines,
+			fmt.Sprintf("page %d/%d pos %d", v.index+1, v.book.Page(), v.pos),
+			fmt.Sprintf("offset %d/%d width %d", v.offset, v.MaxOffset(), v.width),
+			fmt.Sprintf("textures %d failed %d minimap %d", len(v.textures), len(v.failed), len(p.scrollMenu.textures)),
+		)
+	}
+	return p.ui.Children(), lines
+}
+
+func (p *Player) updateGamepad() error {
+
+	g := p.gamepad
+	if g.JustPressed(GamepadMenu) {
+		p.topMenu.Toggle()
+	}
+
+	if p.topMenu.state == MenuActiveState {
+		if g.JustPressed(GamepadFocusPrev) {
+			p.topMenu.FocusPrev()
+		}
+		if g.JustPressed(GamepadFocusNext) {
+			p.topMenu.FocusNext()
+		}
+		if g.JustPressed(GamepadActivate) {
+			err := p.topMenu.Activate()
+			if err != nil {
+				return xerrors.Errorf("topMenu.Activate() error: %w", err)
+			}
+		}
+		return nil
+	}
+
+	if !p.isView() {
+		return nil
+	}
+
+	v := p.viewer
+	if dy := g.ScrollPixel(); dy != 0 {
+		v.Scroll(dy)
+	}
+
+	switch {
+	case g.JustPressed(GamepadPrevPage):
+		v.Jump(v.index-1, 0)
+	case g.JustPressed(GamepadNextPage):
+		v.Jump(v.index+1, 0)
+	case g.JustPressed(GamepadPrevChapter):
+		v.PrevChapter()
+	case g.JustPressed(GamepadNextChapter):
+		v.NextChapter()
+	}
+	return nil
+}
+
+func (p *Player) Draw(screen *ebiten.Image) error {
 
+	screen.Fill(config.Get().Background)
 	if p.isView() {
 		p.viewer.Draw(screen)
 	}
 
-	p.topMenu.Draw(screen)
-	if p.isView() {
-		p.scrollMenu.Draw(screen)
+	err := p.ui.Draw(screen)
+	if err != nil {
+		return xerrors.Errorf("ui Draw() error: %w", err)
 	}
-
-	p.controllMenu.Draw(screen)
-
-	setDebugDisplay(screen)
+	p.drawPreview(screen)
+	return nil
 }
 
 func (p *Player) isView() bool {
@@ -0,0 +1,220 @@
+package wtv
+
+import (
+	"fmt"
+	"sync"
+	"wtv/book"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/xerrors"
+)
+
+const (
+	ProblemsKey          = ebiten.KeyP
+	ProblemsHeaderHeight = 50
+	ProblemsRowHeight    = 28
+)
+
+// ProblemReport lists the pages failed to load.
+// The double click opens the page, Check decodes all the pages.
+type ProblemReport struct {
+	scenes *SceneManager
+	book   *book.Book
+
+	header *Panel
+	title  *Label
+	list   *List
+	events *EventDispatcher
+
+	items    []book.Problem
+	selected func(int) error
+
+	mutex    sync.Mutex
+	checking bool
+	checked  int
+
+	width  int
+	height int
+}
+
+func NewProblemReport(selected func(int) error) *ProblemReport {
+
+	var r ProblemReport
+	r.selected = selected
+	r.events = NewEventDispatcher()
+
+	r.header = NewPanel(0, 0, 0, dp(ProblemsHeaderHeight))
+	r.title = NewLabel("Problems", 0, 0, dp(300), dp(30))
+	r.title.SetScale(FontLargeScale)
+	checkBtn := NewTextButton("Check", 0, 0, dp(90), dp(30))
+	checkBtn.Click(func() error {
+		r.check()
+		return nil
+	})
+	closeBtn := NewTextButton("Close", 0, 0, dp(90), dp(30))
+	closeBtn.Click(func() error {
+		return r.Close()
+	})
+	spacer := NewSpacer(0, 0)
+	r.header.Add(r.title)
+	r.header.Add(spacer)
+	r.header.Add(checkBtn)
+	r.header.Add(closeBtn)
+
+	l := NewHBox()
+	l.Padding = NewPadding(dp(10))
+	l.Spacing = dp(10)
+	l.Grow(spacer, 1)
+	r.header.SetLayout(l)
+
+	r.list = NewList(0, dp(ProblemsHeaderHeight), 0, 0, dp(ProblemsRowHeight))
+	r.list.Activated(func(idx int) error {
+		if idx >= len(r.items) {
+			return nil
+		}
+		err := r.selected(r.items[idx].Index)
+		if err != nil {
+			return xerrors.Errorf("selected() error: %w", err)
+		}
+		return r.Close()
+	})
+	return &r
+}
+
+// Show sets the book before the report is pushed.
+func (r *ProblemReport) Show(b *book.Book) {
+	if r.book != b {
+		r.mutex.Lock()
+		r.checking = false
+		r.mutex.Unlock()
+	}
+	r.book = b
+	r.refresh()
+}
+
+func (r *ProblemReport) refresh() {
+	r.items = nil
+	if r.book != nil {
+		r.items = r.book.Problems()
+	}
+	items := make([]string, len(r.items))
+	for idx, p := range r.items {
+		items[idx] = p.String()
+	}
+	r.list.SetRows(len(items), TextRows(items))
+}
+
+// check decodes the pages of the book in the background.
+func (r *ProblemReport) check() {
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.book == nil || r.checking {
+		return
+	}
+	r.checking = true
+	r.checked = 0
+
+	b := r.book
+	go func() {
+		b.Check(func(done int) {
+			r.mutex.Lock()
+			r.checked = done
+			r.mutex.Unlock()
+		})
+		r.mutex.Lock()
+		r.checking = false
+		r.mutex.Unlock()
+	}()
+}
+
+func (r *ProblemReport) Enter(m *SceneManager) error {
+	r.scenes = m
+	return nil
+}
+
+func (r *ProblemReport) Leave() error {
+	return nil
+}
+
+func (r *ProblemReport) Close() error {
+	if r.scenes == nil {
+		return nil
+	}
+	return r.scenes.Remove(r)
+}
+
+func (r *ProblemReport) Update(w, h int) error {
+
+	if r.width != w || r.height != h {
+		r.width, r.height = w, h
+		r.header.Set(w, dp(ProblemsHeaderHeight))
+		r.list.Set(w, h-dp(ProblemsHeaderHeight))
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) || inpututil.IsKeyJustPressed(ProblemsKey) {
+		return r.Close()
+	}
+
+	//the problems are added by the loaders and the check
+	if r.book != nil && r.book.ProblemCount() != len(r.items) {
+		r.refresh()
+	}
+
+	r.mutex.Lock()
+	title := fmt.Sprintf("Problems (%d)", len(r.items))
+	if r.checking && r.book != nil {
+		title = fmt.Sprintf("Checking %d/%d (%d)", r.checked, r.book.Page(), len(r.items))
+	}
+	r.mutex.Unlock()
+	r.title.SetText(title)
+
+	ebiten.SetCursorShape(ebiten.CursorShapeDefault)
+
+	x, y := ebiten.CursorPosition()
+	targets := []Component{r.list, r.header}
+	err := r.events.Dispatch(targets, x, y)
+	if err != nil {
+		return xerrors.Errorf("Dispatch() error: %w", err)
+	}
+	for _, c := range targets {
+		err := c.Update(x, y)
+		if err != nil {
+			return xerrors.Errorf("Update() error: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *ProblemReport) Draw(screen *ebiten.Image) error {
+
+	screen.Fill(theme.Background)
+
+	err := r.list.Draw(screen)
+	if err != nil {
+		return xerrors.Errorf("list Draw() error: %w", err)
+	}
+
+	if len(r.items) == 0 {
+		msg := "No problems found. Check decodes all the pages."
+		tw := text.BoundString(defaultFont, msg).Dx()
+		text.Draw(screen, msg, defaultFont, (r.width-tw)/2, r.height/2, theme.Text)
+	}
+
+	ebitenutil.DrawRect(screen, 0, 0, float64(r.width), float64(dp(ProblemsHeaderHeight)), theme.Header)
+	err = r.header.Draw(screen)
+	if err != nil {
+		return xerrors.Errorf("header Draw() error: %w", err)
+	}
+	return nil
+}
+
+func (r *ProblemReport) Inspect() ([]Component, []string) {
+	return []Component{r.list, r.header}, []string{
+		fmt.Sprintf("problems %d", len(r.items)),
+		fmt.Sprintf("hover %T", r.events.Hover()),
+	}
+}
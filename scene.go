@@ -1,4 +1,183 @@
 package wtv
 
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"golang.org/x/xerrors"
+)
+
 type Scene interface {
+	Update(int, int) error
+	Draw(*ebiten.Image) error
+}
+
+// SceneHook is called when the scene is pushed to or removed from the stack.
+type SceneHook interface {
+	Enter(*SceneManager) error
+	Leave() error
+}
+
+// SceneLayout receives the screen size in the device pixels.
+type SceneLayout interface {
+	Layout(int, int) (int, int)
+}
+
+// Overlay is drawn over the scene under it.
+// In Components, it is drawn over the siblings.
+type Overlay interface {
+	Overlay() bool
+}
+
+const SceneTransitionFrames = 15
+
+// SceneManager is the ebiten.Game of the scene stack.
+// Only the top scene receives Update.
+type SceneManager struct {
+	stack []Scene
+
+	width  int
+	height int
+
+	transition int
+}
+
+func NewSceneManager(root Scene) (*SceneManager, error) {
+	var m SceneManager
+	err := m.Push(root)
+	if err != nil {
+		return nil, xerrors.Errorf("Push() error: %w", err)
+	}
+	m.transition = 0
+	return &m, nil
+}
+
+func (m *SceneManager) Top() Scene {
+	if len(m.stack) == 0 {
+		return nil
+	}
+	return m.stack[len(m.stack)-1]
+}
+
+func (m *SceneManager) Push(s Scene) error {
+	m.stack = append(m.stack, s)
+	if h, ok := s.(SceneHook); ok {
+		err := h.Enter(m)
+		if err != nil {
+			return xerrors.Errorf("Enter() error: %w", err)
+		}
+	}
+	m.startTransition()
+	return nil
+}
+
+// Pop removes the top scene. The root scene is not removed.
+func (m *SceneManager) Pop() error {
+	if len(m.stack) <= 1 {
+		return nil
+	}
+	s := m.Top()
+	m.stack = m.stack[:len(m.stack)-1]
+	if h, ok := s.(SceneHook); ok {
+		err := h.Leave()
+		if err != nil {
+			return xerrors.Errorf("Leave() error: %w", err)
+		}
+	}
+	m.startTransition()
+	return nil
+}
+
+// Remove pops the scenes until s is removed.
+func (m *SceneManager) Remove(s Scene) error {
+	for idx := len(m.stack) - 1; idx > 0; idx-- {
+		if m.stack[idx] != s {
+			continue
+		}
+		for len(m.stack) > idx {
+			err := m.Pop()
+			if err != nil {
+				return xerrors.Errorf("Pop() error: %w", err)
+			}
+		}
+		break
+	}
+	return nil
+}
+
+func isOverlay(v interface{}) bool {
+	o, ok := v.(Overlay)
+	return ok && o.Overlay()
+}
+
+func (m *SceneManager) startTransition() {
+	if !isOverlay(m.Top()) {
+		m.transition = SceneTransitionFrames
+	}
+}
+
+// Layout makes the screen in the device pixels for HiDPI displays.
+// The cursor position is in the same pixels.
+func (m *SceneManager) Layout(ow, oh int) (int, int) {
+	w, h := toDevice(ow), toDevice(oh)
+	m.width, m.height = w, h
+	for _, s := range m.stack {
+		if l, ok := s.(SceneLayout); ok {
+			l.Layout(w, h)
+		}
+	}
+	return w, h
+}
+
+func (m *SceneManager) Update() error {
+
+	if m.transition > 0 {
+		m.transition--
+	}
+
+	if inpututil.IsKeyJustPressed(DebugKey) {
+		SetDebug(!debugMode)
+	}
+	toasts.Update()
+
+	s := m.Top()
+	if s == nil {
+		return nil
+	}
+	err := s.Update(m.width, m.height)
+	if err != nil {
+		return xerrors.Errorf("Scene Update() error: %w", err)
+	}
+	return nil
+}
+
+func (m *SceneManager) Draw(screen *ebiten.Image) {
+
+	//draw from the scene that covers the screen
+	start := len(m.stack) - 1
+	for start > 0 && isOverlay(m.stack[start]) {
+		start--
+	}
+
+	for idx := start; idx < len(m.stack); idx++ {
+		err := m.stack[idx].Draw(screen)
+		if err != nil {
+			logger.Error("scene draw error", F("scene", fmt.Sprintf("%T", m.stack[idx])), F("err", err))
+		}
+
+		if idx == start && m.transition > 0 {
+			a := uint8(255 * m.transition / SceneTransitionFrames)
+			ebitenutil.DrawRect(screen, 0, 0, float64(m.width), float64(m.height), color.RGBA{0, 0, 0, a})
+		}
+	}
+
+	toasts.Draw(screen, m.width, m.height)
+
+	if debugMode {
+		dw.Draw(screen)
+		drawDebug(screen, m.Top())
+	}
 }
@@ -1,27 +1,47 @@
 package wtv
 
 import (
+	"fmt"
 	"image"
-	"image/draw"
-	"sync"
+	"image/color"
+	"wtv/book"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/text"
 	"golang.org/x/xerrors"
 )
 
+const (
+	ScrollMenuPrefetch   = 2
+	ScrollMenuCacheRange = 20
+)
+
+// ScrollMenu is the minimap of the book.
+// Pages are decoded and scaled by the background loader and drawn as soon as they arrive.
+// The minimap and the Viewer share the Document of the book,
+// a minimap offset is a viewer offset scaled by the width.
 type ScrollMenu struct {
-	loading sync.Once
+	book   *book.Book
+	top    int
+	loaded bool
+
+	viewWidth  int
+	viewHeight int
+	viewTop    int
 
-	index     int
-	start     int
-	scrollMap map[int]int
+	dragging  bool
+	dragStart int
+
+	width    int
+	loader   *PageLoader
+	textures map[int]*ebiten.Image
+	failed   map[int]error
+	err      error
 
 	selectedIndex int
 	selectedPos   int
 
-	img *ebiten.Image
-
 	*Menu
 }
 
@@ -30,194 +50,228 @@ func NewScrollMenu(m *Menu) *ScrollMenu {
 	sm.Menu = m
 	sm.selectedIndex = -1
 	sm.selectedPos = -1
+	sm.loader = NewPageLoader(func(img image.Image, w int) image.Image {
+		return book.Scale(img, float64(w)/float64(img.Bounds().Dx()))
+	})
+	sm.clear()
 	return &sm
 }
 
-func (sm *ScrollMenu) Load(b *Book, idx int, width, pos int) error {
-	sm.loading.Do(func() {
-		sm.load(b, idx, width, pos)
-	})
-	return nil
+func (sm *ScrollMenu) clear() {
+	sm.textures = make(map[int]*ebiten.Image)
+	sm.failed = make(map[int]error)
 }
 
-func (sm *ScrollMenu) load(b *Book, idx int, width, pos int) error {
-
-	mib := sm.Menu.img.Bounds()
-	w := mib.Dx()
-	h := mib.Dy()
+func (sm *ScrollMenu) size() (int, int) {
+	if sm.Menu.img == nil {
+		return 0, 0
+	}
+	b := sm.Menu.img.Bounds()
+	return b.Dx(), b.Dy()
+}
 
-	sm.scrollMap = make(map[int]int)
+// Load starts the loading around the viewer offset.
+// It does not block, the error of the background loader is returned.
+func (sm *ScrollMenu) Load(b *book.Book, offset, width, height int) error {
 
-	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	w, h := sm.size()
+	if w == 0 || width == 0 {
+		return nil
+	}
 
-	img1, err := b.Load(idx)
-	if err != nil {
-		return xerrors.Errorf("Book Load() error: %w", err)
+	if sm.loader.Set(b, w) {
+		sm.book = b
+		sm.width = w
+		sm.clear()
 	}
 
-	half := h / 2
-	b1 := img1.Bounds()
+	sm.viewWidth = width
+	sm.viewHeight = height
 
-	//現在表示中の倍率を取得
-	orgS := float64(width) / float64(b1.Dx())
-	//画面サイズの高さを実際の中央位置を算出
-	orgP := float64(pos+half) / orgS
+	if !sm.loaded {
+		//現在表示中の中央を半分の位置に表示
+		sm.viewTop = sm.toMinimap(offset)
+		sm.top = sm.viewTop + sm.toMinimap(height)/2 - h/2
+		sm.loaded = true
+	}
 
-	//元の画像での座標を算出
-	scale := float64(w) / float64(b1.Dx())
-	p1 := Scale(img1, scale)
-	pb1 := p1.Bounds()
+	sm.collect()
+	sm.request()
 
-	p := int(orgP * scale)
+	err := sm.err
+	sm.err = nil
+	return err
+}
 
-	//現状のpを半分の位置に表示
-	startY := half - p
-	sm.index = idx
+// toMinimap converts the viewer length to the minimap length.
+func (sm *ScrollMenu) toMinimap(v int) int {
+	return int(float64(v) * float64(sm.width) / float64(sm.viewWidth))
+}
 
-	sm.start = startY
+// toViewer converts the minimap length to the viewer length.
+func (sm *ScrollMenu) toViewer(v int) int {
+	return int(float64(v) * float64(sm.viewWidth) / float64(sm.width))
+}
 
-	draw.Draw(
-		img,
-		image.Rect(0, startY, pb1.Dx(), pb1.Dy()+startY),
-		p1, image.Point{0, 0}, draw.Over)
-	sm.scrollMap[idx] = pb1.Dy()
+func (sm *ScrollMenu) collect() {
 
-	y := startY
-	for i := idx - 1; i >= 0; i-- {
-		prev, err := b.Load(i)
-		if err != nil {
-			return xerrors.Errorf("book preb load() error: %w", err)
+	for _, page := range sm.loader.Collect() {
+		if page.err != nil {
+			sm.failed[page.index] = page.err
+			sm.err = page.err
+			continue
 		}
-		prevB := prev.Bounds()
-		prevS := float64(w) / float64(prevB.Dx())
-
-		ps := Scale(prev, prevS)
-
-		y = y - ps.Bounds().Dy()
-		py := 0
+		sm.textures[page.index] = ebiten.NewImageFromImage(page.img)
+	}
+}
 
-		dy := ps.Bounds().Dy()
-		sm.scrollMap[i] = dy
+func (sm *ScrollMenu) request() {
 
-		if y < 0 {
-			dy = dy + y
-			py = y * -1
-			y = 0
+	min, max := -1, -1
+	sm.each(func(idx, y, ph int) {
+		if min == -1 || idx < min {
+			min = idx
 		}
+		if idx > max {
+			max = idx
+		}
+	})
+	if min == -1 {
+		return
+	}
 
-		draw.Draw(
-			img,
-			image.Rect(0, y, prevB.Dx(), y+dy),
-			ps, image.Point{0, py}, draw.Over)
-
-		if y <= 0 {
-			break
+	for idx := min - ScrollMenuPrefetch; idx <= max+ScrollMenuPrefetch; idx++ {
+		if idx < 0 || idx >= sm.book.Page() {
+			continue
+		}
+		if _, ok := sm.textures[idx]; ok || sm.failed[idx] != nil {
+			continue
 		}
+		sm.loader.Request(idx)
 	}
 
-	y = startY + pb1.Dy()
-	for i := idx + 1; i < b.Page(); i++ {
-		next, err := b.Load(i)
-		if err != nil {
-			return xerrors.Errorf("book preb load() error: %w", err)
+	for idx := range sm.textures {
+		if idx < min-ScrollMenuCacheRange || idx > max+ScrollMenuCacheRange {
+			delete(sm.textures, idx)
 		}
-		nextB := next.Bounds()
-		nextS := float64(w) / float64(nextB.Dx())
+	}
+}
 
-		ns := Scale(next, nextS)
+// each calls fn with the visible pages and the top on the minimap.
+func (sm *ScrollMenu) each(fn func(idx, y, ph int)) {
 
-		dy := ns.Bounds().Dy()
-		sm.scrollMap[i] = dy
+	if sm.book == nil {
+		return
+	}
+	w, h := sm.size()
+	doc := sm.book.Document()
+
+	idx, pos := doc.Locate(sm.top, w)
+	for y := -pos; idx < doc.Pages() && y < h; idx++ {
+		ph := doc.PageHeight(idx, w)
+		fn(idx, y, ph)
+		y += ph
+	}
+}
 
-		if y+dy > h {
-			dy = h - y
-		}
+func (sm *ScrollMenu) scroll(dy int) {
 
-		draw.Draw(
-			img,
-			image.Rect(0, y, nextB.Dx(), y+dy),
-			ns, image.Point{0, 0}, draw.Over)
+	if sm.book == nil {
+		return
+	}
+	w, h := sm.size()
 
-		if y >= h {
-			break
-		}
-		y = y + dy
+	sm.top += dy
+	if max := sm.book.Document().Length(w) - h/2; sm.top > max {
+		sm.top = max
+	}
+	if sm.top < -h/2 {
+		sm.top = -h / 2
 	}
+}
 
-	sm.img = ebiten.NewImageFromImage(img)
-	return nil
+// selectAt selects the viewer position whose center is the minimap offset.
+func (sm *ScrollMenu) selectAt(off int) {
+	v := sm.toViewer(off) - sm.viewHeight/2
+	if v < 0 {
+		v = 0
+	}
+	sm.selectedIndex, sm.selectedPos = sm.book.Document().Locate(v, sm.viewWidth)
 }
 
 func (sm *ScrollMenu) Reset() {
 	if sm == nil {
 		return
 	}
-
-	if sm.img != nil {
-		sm.loading = sync.Once{}
-		sm.img = nil
-		sm.selectedIndex = -1
-		sm.selectedPos = -1
-		sm.scrollMap = make(map[int]int)
-	}
+	sm.loaded = false
+	sm.dragging = false
+	sm.selectedIndex = -1
+	sm.selectedPos = -1
 }
 
-func (sm *ScrollMenu) Update(w, h int) error {
+func (sm *ScrollMenu) Update(x, y int) error {
 
-	err := sm.Menu.Update(w, h)
+	err := sm.Menu.Update(x, y)
 	if err != nil {
 		return xerrors.Errorf("Update() error: %w", err)
 	}
 
-	if sm.Active() {
+	if sm.state == MenuActiveState {
 
 		ebiten.SetCursorShape(ebiten.CursorShapePointer)
 
-		if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		_, dy := ebiten.Wheel()
+		if dy != 0 {
+			sm.scroll(int(dy * -dpf(40)))
+		}
+	}
 
-			half := h / 2
-			updown := sm.Menu.relativeY - half
-			extent := 1
-			if updown <= 0 {
-				extent = -1
-			}
+	//while dragging, follow the cursor outside of the menu
+	if sm.dragging {
+		my := y - sm.Menu.y + sm.top
+		sm.viewTop, sm.dragStart = sm.viewTop+my-sm.dragStart, my
+	}
+	return nil
+}
 
-			idx := sm.index
-			pos := 0
-
-			for {
-
-				l, ok := sm.scrollMap[idx]
-				if !ok {
-					break
-				}
-
-				s := h / l
-
-				updown = updown + l*extent*-1
-				if extent <= 0 {
-					if updown > 0 {
-						logger.Println(s, updown)
-						pos = updown*s - h
-						break
-					}
-				} else {
-					if updown < 0 {
-						pos = updown*s - h
-						break
-					}
-				}
-
-				idx = idx + extent
-			}
+// HandleEvent moves the viewport rectangle.
+// Click outside of the viewport jumps there, dragging the viewport jumps at release.
+func (sm *ScrollMenu) HandleEvent(e *Event) error {
 
-			sm.selectedIndex = idx
-			sm.selectedPos = pos
+	err := sm.Menu.HandleEvent(e)
+	if err != nil {
+		return xerrors.Errorf("Menu.HandleEvent() error: %w", err)
+	}
 
-			logger.Println(idx, pos)
-		}
+	if sm.book == nil || !sm.loaded {
+		return nil
 	}
 
+	y := e.Y + sm.top
+	vh := sm.toMinimap(sm.viewHeight)
+
+	switch e.Type {
+	case MouseDownEvent:
+		if sm.state != MenuActiveState {
+			return nil
+		}
+		e.Consume()
+		if y >= sm.viewTop && y < sm.viewTop+vh {
+			sm.dragging = true
+			sm.dragStart = y
+			return nil
+		}
+		sm.selectAt(y)
+		logger.Debug("minimap select", F("index", sm.selectedIndex), F("pos", sm.selectedPos))
+	case MouseUpEvent:
+		if !sm.dragging {
+			return nil
+		}
+		e.Consume()
+		sm.dragging = false
+		sm.selectAt(sm.viewTop + vh/2)
+		logger.Debug("minimap select", F("index", sm.selectedIndex), F("pos", sm.selectedPos))
+	}
 	return nil
 }
 
@@ -225,12 +279,52 @@ func (sm *ScrollMenu) Active() bool {
 	return sm.Menu.Active()
 }
 
-func (sm *ScrollMenu) Draw(img *ebiten.Image) {
-	if sm.Active() {
-		if sm.img != nil {
-			op := &ebiten.DrawImageOptions{}
-			sm.Menu.img.DrawImage(sm.img, op)
-		}
-		sm.Menu.Draw(img)
+func (sm *ScrollMenu) Draw(img *ebiten.Image) error {
+
+	if !sm.Active() || sm.book == nil {
+		return nil
+	}
+
+	if sm.loaded && sm.Menu.img != nil {
+		dst := sm.Menu.img
+		w, _ := sm.size()
+		sm.each(func(idx, y, ph int) {
+			if tex, ok := sm.textures[idx]; ok {
+				op := &ebiten.DrawImageOptions{}
+				op.GeoM.Translate(0, float64(y))
+				dst.DrawImage(tex, op)
+				return
+			}
+
+			clr := color.Color(theme.Placeholder)
+			label := "..."
+			if sm.failed[idx] != nil {
+				clr = theme.Error
+				label = fmt.Sprintf("%d !", idx+1)
+			}
+			ebitenutil.DrawRect(dst, 0, float64(y), float64(w), float64(ph), clr)
+			ebitenutil.DrawRect(dst, 0, float64(y+ph)-dpf(1), float64(w), dpf(1), theme.MenuBackground)
+			text.Draw(dst, label, defaultFont, dp(4), y+dp(20), theme.Text)
+		})
+		sm.drawViewport(dst)
 	}
+	return sm.Menu.Draw(img)
+}
+
+func (sm *ScrollMenu) drawViewport(dst *ebiten.Image) {
+
+	w, _ := sm.size()
+	y := float64(sm.viewTop - sm.top)
+	vh := float64(sm.toMinimap(sm.viewHeight))
+	fw := float64(w)
+
+	clr := theme.Active
+	fill := color.RGBA(clr)
+	fill.A = 40
+	ebitenutil.DrawRect(dst, 0, y, fw, vh, fill)
+	b := dpf(2)
+	ebitenutil.DrawRect(dst, 0, y, fw, b, clr)
+	ebitenutil.DrawRect(dst, 0, y+vh-b, fw, b, clr)
+	ebitenutil.DrawRect(dst, 0, y, b, vh, clr)
+	ebitenutil.DrawRect(dst, fw-b, y, b, vh, clr)
 }
@@ -0,0 +1,100 @@
+package wtv
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"golang.org/x/xerrors"
+)
+
+// ScrollContainer is a Panel that scrolls the children vertically.
+// The children are arranged by the layout and moved by the scroll.
+type ScrollContainer struct {
+	*Panel
+
+	scroll  int
+	content int
+	hover   bool
+}
+
+func NewScrollContainer(x, y, w, h int) *ScrollContainer {
+	var s ScrollContainer
+	s.Panel = NewPanel(x, y, w, h)
+	s.Panel.Components.parent = &s
+	return &s
+}
+
+func (s *ScrollContainer) Set(w, h int) {
+	s.w = w
+	s.h = h
+	s.arrange()
+}
+
+// arrange moves the children by the scroll and measures the content.
+func (s *ScrollContainer) arrange() {
+
+	if s.layout == nil {
+		return
+	}
+	s.layout.Arrange(s.children, 0, -s.scroll, s.w-dp(ScrollbarWidth), s.h)
+
+	s.content = 0
+	for _, c := range s.children {
+		_, y := c.Point()
+		_, h := c.Size()
+		if b := int(y) + h + s.scroll; b > s.content {
+			s.content = b
+		}
+	}
+}
+
+func (s *ScrollContainer) Scroll(dy int) {
+	s.scroll += dy
+	if max := s.content - s.h; s.scroll > max {
+		s.scroll = max
+	}
+	if s.scroll < 0 {
+		s.scroll = 0
+	}
+	s.arrange()
+}
+
+// ScrollTop goes back to the top.
+func (s *ScrollContainer) ScrollTop() {
+	s.Scroll(-s.scroll)
+}
+
+func (s *ScrollContainer) HandleEvent(e *Event) error {
+	switch e.Type {
+	case MouseEnterEvent:
+		s.hover = true
+	case MouseLeaveEvent:
+		s.hover = false
+	}
+	return nil
+}
+
+func (s *ScrollContainer) Update(x, y int) error {
+
+	if s.hover {
+		_, dy := ebiten.Wheel()
+		if dy != 0 {
+			s.Scroll(int(dy * -float64(dp(ScrollWheelSpeed))))
+		}
+	}
+
+	err := s.Panel.Update(x, y)
+	if err != nil {
+		return xerrors.Errorf("Panel.Update() error: %w", err)
+	}
+	return nil
+}
+
+func (s *ScrollContainer) Draw(img *ebiten.Image) error {
+
+	err := s.Panel.Draw(img)
+	if err != nil {
+		return xerrors.Errorf("Panel.Draw() error: %w", err)
+	}
+
+	drawScrollbar(img, s.x+s.w-dp(ScrollbarWidth), s.y, s.h, s.scroll, s.content)
+	return nil
+}
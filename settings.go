@@ -0,0 +1,395 @@
+package wtv
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"wtv/config"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/xerrors"
+)
+
+const (
+	SettingsHeaderHeight = 50
+	SettingsLabelWidth   = 200
+	SettingsRowHeight    = 36
+	SettingsFieldHeight  = 28
+	SettingsFieldWidth   = 300
+	//ebiten panics with the window size 0
+	SettingsMinWindowSize = 100
+)
+
+var (
+	directionNames = []string{"Up", "Down", "Left", "Right"}
+	effectNames    = []string{"Fadein", "Scroll"}
+	//index is config.SortType
+	sortNames = []string{"Numeric", "Numeric (desc)", "Alphanumeric", "Alphanumeric (desc)", "Modtime", "Modtime (desc)", "None"}
+)
+
+// Settings is the scene to edit the config.
+// A change is applied and saved at once.
+type Settings struct {
+	scenes *SceneManager
+
+	header *Panel
+	form   *ScrollContainer
+	events *EventDispatcher
+
+	message string
+	width   int
+	height  int
+
+	sort  func(config.SortType) error
+	apply func() error
+
+	//refresh sets the current config to the fields
+	refresh []func()
+}
+
+// NewSettings receives the functions to apply the sort and the other settings.
+func NewSettings(sort func(config.SortType) error, apply func() error) *Settings {
+
+	var s Settings
+	s.sort = sort
+	s.apply = apply
+	s.events = NewEventDispatcher()
+
+	s.header = NewPanel(0, 0, 0, dp(SettingsHeaderHeight))
+	title := NewLabel("Settings", 0, 0, dp(200), dp(30))
+	title.SetScale(FontLargeScale)
+	closeBtn := NewTextButton("Close", 0, 0, dp(90), dp(30))
+	closeBtn.Click(func() error {
+		return s.Close()
+	})
+	spacer := NewSpacer(0, 0)
+	s.header.Add(title)
+	s.header.Add(spacer)
+	s.header.Add(closeBtn)
+
+	hl := NewHBox()
+	hl.Padding = NewPadding(dp(10))
+	hl.Grow(spacer, 1)
+	s.header.SetLayout(hl)
+
+	s.form = NewScrollContainer(0, dp(SettingsHeaderHeight), 0, 0)
+	fl := NewFormLayout(dp(SettingsLabelWidth), dp(SettingsRowHeight))
+	fl.Padding = Padding{Top: dp(10), Left: dp(20), Right: dp(20), Bottom: dp(10)}
+	s.form.SetLayout(fl)
+
+	s.build()
+	return &s
+}
+
+func (s *Settings) build() {
+
+	//all the fields of the config are shown,
+	//Direction, Effect and Fit mode are saved for the viewer modes not read yet(it scrolls down fit to the width)
+	s.section("View")
+	s.dropdown("Direction", directionNames,
+		func(c *config.Config) int { return int(c.Direction) },
+		func(c *config.Config, v int) { c.Direction = config.Direction(v) })
+	s.dropdown("Effect", effectNames,
+		func(c *config.Config) int { return int(c.Effect) },
+		func(c *config.Config, v int) { c.Effect = config.Effect(v) })
+	s.checkbox("Fit mode", "fit to the width",
+		func(c *config.Config) bool { return c.FitMode },
+		func(c *config.Config, v bool) { c.FitMode = v })
+	s.number("Window width", false, SettingsMinWindowSize,
+		func(c *config.Config) int { return c.Width },
+		func(c *config.Config, v int) {
+			c.Width = v
+			ebiten.SetWindowSize(c.Width, c.Height)
+		})
+	s.number("Window height", false, SettingsMinWindowSize,
+		func(c *config.Config) int { return c.Height },
+		func(c *config.Config, v int) {
+			c.Height = v
+			ebiten.SetWindowSize(c.Width, c.Height)
+		})
+	s.text("Background", true,
+		func(c *config.Config) string { return colorHex(c.Background) },
+		func(c *config.Config, v string) error {
+			clr, err := parseColorHex(v)
+			if err != nil {
+				return err
+			}
+			c.Background = clr
+			return nil
+		})
+
+	s.text("Theme", false,
+		func(c *config.Config) string { return c.Theme },
+		func(c *config.Config, v string) error {
+			_, err := LoadTheme(v)
+			if err != nil {
+				return xerrors.Errorf("LoadTheme() error: %w", err)
+			}
+			c.Theme = v
+			return nil
+		})
+
+	s.text("Fonts", false,
+		func(c *config.Config) string { return strings.Join(c.Fonts, string(filepath.ListSeparator)) },
+		func(c *config.Config, v string) error {
+			files := filepath.SplitList(v)
+			for _, name := range files {
+				_, err := parseFontFile(name)
+				if err != nil {
+					return xerrors.Errorf("parseFontFile(%s) error: %w", name, err)
+				}
+			}
+			c.Fonts = files
+			return nil
+		})
+
+	s.section("Reading")
+	sortDD := NewDropdown(sortNames, 0, 0, dp(SettingsFieldWidth), dp(SettingsFieldHeight))
+	sortDD.Changed(func(v int) error {
+		return s.sort(config.SortType(v))
+	})
+	s.refresh = append(s.refresh, func() {
+		sortDD.Select(int(config.Get().Sort))
+	})
+	s.add("Sort", sortDD)
+	s.number("Auto scroll speed", true, 1,
+		func(c *config.Config) int { return c.AutoScrollSpeed },
+		func(c *config.Config, v int) { c.AutoScrollSpeed = v })
+	s.number("Prefetch pages", true, 1,
+		func(c *config.Config) int { return c.Prefetch },
+		func(c *config.Config, v int) { c.Prefetch = v })
+
+	s.section("Export")
+	s.checkbox("ComicInfo", "write ComicInfo.xml",
+		func(c *config.Config) bool { return c.ExportComicInfo },
+		func(c *config.Config, v bool) { c.ExportComicInfo = v })
+
+	s.section("Cache")
+	//empty is the default, it is shown as the placeholder
+	cache := s.text("Cache directory", false,
+		func(c *config.Config) string { return c.CacheDirectory },
+		func(c *config.Config, v string) error {
+			if v == "" {
+				c.CacheDirectory = ""
+				return nil
+			}
+			err := os.MkdirAll(v, 0777)
+			if err != nil {
+				return xerrors.Errorf("os.MkdirAll() error: %w", err)
+			}
+			c.CacheDirectory = v
+			return nil
+		})
+	s.refresh = append(s.refresh, func() {
+		if config.Get().CacheDirectory == "" {
+			cache.SetPlaceholder(config.CacheDir())
+		}
+	})
+
+	s.section("Gamepad")
+	s.number("Scroll axis", true, 0,
+		func(c *config.Config) int { return c.Gamepad.ScrollAxis },
+		func(c *config.Config, v int) { c.Gamepad.ScrollAxis = v })
+	s.number("Deadzone (%)", true, 0,
+		func(c *config.Config) int { return int(c.Gamepad.Deadzone * 100) },
+		func(c *config.Config, v int) { c.Gamepad.Deadzone = float64(v) / 100 })
+	s.number("Scroll speed", true, 1,
+		func(c *config.Config) int { return c.Gamepad.ScrollSpeed },
+		func(c *config.Config, v int) { c.Gamepad.ScrollSpeed = v })
+	buttons := []struct {
+		label string
+		field func(c *config.Config) *int
+	}{
+		{"Prev page", func(c *config.Config) *int { return &c.Gamepad.PrevPage }},
+		{"Next page", func(c *config.Config) *int { return &c.Gamepad.NextPage }},
+		{"Prev chapter", func(c *config.Config) *int { return &c.Gamepad.PrevChapter }},
+		{"Next chapter", func(c *config.Config) *int { return &c.Gamepad.NextChapter }},
+		{"Menu", func(c *config.Config) *int { return &c.Gamepad.Menu }},
+		{"Focus prev", func(c *config.Config) *int { return &c.Gamepad.FocusPrev }},
+		{"Focus next", func(c *config.Config) *int { return &c.Gamepad.FocusNext }},
+		{"Activate", func(c *config.Config) *int { return &c.Gamepad.Activate }},
+	}
+	for _, b := range buttons {
+		field := b.field
+		s.number(b.label, true, 0,
+			func(c *config.Config) int { return *field(c) },
+			func(c *config.Config, v int) { *field(c) = v })
+	}
+}
+
+func (s *Settings) add(label string, field Component) {
+	s.form.Add(NewLabel(label, 0, 0, dp(SettingsLabelWidth), dp(SettingsRowHeight)))
+	s.form.Add(field)
+}
+
+func (s *Settings) section(title string) {
+	l := NewLabel(title, 0, 0, dp(SettingsLabelWidth), dp(SettingsRowHeight))
+	l.SetColor(&theme.Active)
+	s.form.Add(l)
+	s.form.Add(NewSpacer(0, dp(SettingsFieldHeight)))
+}
+
+func (s *Settings) dropdown(label string, names []string,
+	get func(*config.Config) int, set func(*config.Config, int)) {
+
+	d := NewDropdown(names, 0, 0, dp(SettingsFieldWidth), dp(SettingsFieldHeight))
+	d.Changed(func(v int) error {
+		set(config.Get(), v)
+		return s.save()
+	})
+	s.refresh = append(s.refresh, func() {
+		d.Select(get(config.Get()))
+	})
+	s.add(label, d)
+}
+
+func (s *Settings) checkbox(label, txt string,
+	get func(*config.Config) bool, set func(*config.Config, bool)) {
+
+	c := NewCheckbox(txt, 0, 0)
+	c.Changed(func(v bool) error {
+		set(config.Get(), v)
+		return s.save()
+	})
+	s.refresh = append(s.refresh, func() {
+		c.SetChecked(get(config.Get()))
+	})
+	s.add(label, c)
+}
+
+// number is applied while typing when live, else by the enter key.
+// The value less than min is not set.
+func (s *Settings) number(label string, live bool, min int,
+	get func(*config.Config) int, set func(*config.Config, int)) {
+
+	s.text(label, live,
+		func(c *config.Config) string { return strconv.Itoa(get(c)) },
+		func(c *config.Config, v string) error {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < min {
+				return xerrors.Errorf("invalid number[%s] (%d or more)", v, min)
+			}
+			set(c, n)
+			return nil
+		})
+}
+
+func (s *Settings) text(label string, live bool,
+	get func(*config.Config) string, set func(*config.Config, string) error) *TextInput {
+
+	t := NewTextInput(0, 0, dp(SettingsFieldWidth), dp(SettingsFieldHeight))
+	fn := func(v string) error {
+		err := set(config.Get(), v)
+		if err != nil {
+			//the wrong value is shown, not an error of the scene
+			s.message = err.Error()
+			return nil
+		}
+		return s.save()
+	}
+	if live {
+		t.Changed(fn)
+	} else {
+		t.Submit(fn)
+	}
+	s.refresh = append(s.refresh, func() {
+		t.SetText(get(config.Get()))
+	})
+	s.add(label, t)
+	return t
+}
+
+func (s *Settings) save() error {
+	s.message = ""
+	err := s.apply()
+	if err != nil {
+		return xerrors.Errorf("apply() error: %w", err)
+	}
+	err = config.Save()
+	if err != nil {
+		return xerrors.Errorf("config.Save() error: %w", err)
+	}
+	return nil
+}
+
+func (s *Settings) Enter(m *SceneManager) error {
+	s.scenes = m
+	s.message = ""
+	for _, fn := range s.refresh {
+		fn()
+	}
+	s.form.ScrollTop()
+	return nil
+}
+
+func (s *Settings) Leave() error {
+	return nil
+}
+
+func (s *Settings) Close() error {
+	if s.scenes == nil {
+		return nil
+	}
+	return s.scenes.Remove(s)
+}
+
+func (s *Settings) Update(w, h int) error {
+
+	if s.width != w || s.height != h {
+		s.width, s.height = w, h
+		s.header.Set(w, dp(SettingsHeaderHeight))
+		s.form.Set(w, h-dp(SettingsHeaderHeight))
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		return s.Close()
+	}
+
+	ebiten.SetCursorShape(ebiten.CursorShapeDefault)
+
+	x, y := ebiten.CursorPosition()
+	targets := []Component{s.form, s.header}
+	err := s.events.Dispatch(targets, x, y)
+	if err != nil {
+		return xerrors.Errorf("Dispatch() error: %w", err)
+	}
+	for _, c := range targets {
+		err := c.Update(x, y)
+		if err != nil {
+			return xerrors.Errorf("Update() error: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *Settings) Draw(screen *ebiten.Image) error {
+
+	screen.Fill(theme.Background)
+
+	err := s.form.Draw(screen)
+	if err != nil {
+		return xerrors.Errorf("form Draw() error: %w", err)
+	}
+
+	ebitenutil.DrawRect(screen, 0, 0, float64(s.width), float64(dp(SettingsHeaderHeight)), theme.Header)
+	err = s.header.Draw(screen)
+	if err != nil {
+		return xerrors.Errorf("header Draw() error: %w", err)
+	}
+
+	if s.message != "" {
+		text.Draw(screen, fitText(s.message, s.width/2), defaultFont, s.width/3, dp(SettingsHeaderHeight)-dp(18), theme.Error)
+	}
+	return nil
+}
+
+func (s *Settings) Inspect() ([]Component, []string) {
+	return []Component{s.form, s.header}, []string{
+		fmt.Sprintf("hover %T", s.events.Hover()),
+	}
+}
@@ -9,6 +9,7 @@ type Shape interface {
 	In(x, y int) bool
 	Move(x, y int) error
 	Point() (float64, float64)
+	Size() (int, int)
 }
 
 type Rectangle struct {
@@ -42,6 +43,10 @@ func (r *Rectangle) Point() (float64, float64) {
 	return float64(r.x), float64(r.y)
 }
 
+func (r *Rectangle) Size() (int, int) {
+	return r.w, r.h
+}
+
 type Circle struct {
 	r int
 	x int
@@ -63,9 +68,10 @@ func (c Circle) In(x, y int) bool {
 	return c.r > int(dr)
 }
 
+// Move is the top-left like Point(), NewCircle() is the center.
 func (c *Circle) Move(x, y int) error {
-	c.x = x
-	c.y = y
+	c.x = x + c.r
+	c.y = y + c.r
 	return nil
 }
 
@@ -73,6 +79,10 @@ func (c *Circle) Point() (float64, float64) {
 	return float64(c.x - c.r), float64(c.y - c.r)
 }
 
+func (c *Circle) Size() (int, int) {
+	return c.r * 2, c.r * 2
+}
+
 /*
 //TODO 実装時は基準点を別に実装した方がいいかも
 
@@ -1,105 +1,229 @@
 package wtv
 
 import (
-	"fmt"
 	"image"
-	"image/color"
-	"log"
+	"sync"
+	"wtv/book"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/xerrors"
 )
 
+// Slider is the position in the whole book(0.0-1.0).
 type Slider struct {
-	max        int
-	current    int
-	changeFunc func(v int) error
-
-	rect image.Rectangle
+	x     int
+	y     int
+	width int
+
+	value      float64
+	label      string
+	ticks      []float64
+	changeFunc func(v float64) error
+
+	dragging bool
+	hover    bool
+	hoverV   float64
 }
 
+const (
+	SliderMargin        = 20
+	SliderLabelWidth    = 100
+	SliderCurrentY      = 30.0
+	SliderCurrentWidth  = 10.0
+	SliderCurrentHeight = 15.0
+)
+
 func NewSlider() *Slider {
 	var s Slider
-	s.current = 1
-	s.max = 1
-
-	s.rect = image.Rect(int(SliderStartX), int(SliderCurrentY),
-		int(SliderWidth)+int(SliderStartX), int(SliderCurrentHeight)+int(SliderCurrentY))
+	s.x = dp(SliderMargin)
+	s.width = 200
 	return &s
 }
 
-func (s *Slider) Changed(fn func(v int) error) {
+func (s *Slider) Changed(fn func(v float64) error) {
 	s.changeFunc = fn
 }
 
-func (s *Slider) SetMax(max int) {
-	s.max = max
+// Set fits the bar to the width, the label is included.
+func (s *Slider) Set(w, h int) {
+	s.width = w - dp(SliderLabelWidth)
+	if min := int(dpf(SliderCurrentWidth)) * 2; s.width < min {
+		s.width = min
+	}
 }
 
-func (s *Slider) Set(w, h int) {
-	//TODO Not Implemented
+func (s *Slider) Size() (int, int) {
+	return s.width + dp(SliderLabelWidth), int(dpf(SliderCurrentY) + dpf(SliderCurrentHeight))
 }
 
-func (s *Slider) SetValue(current int) {
-	s.current = current
+func (s *Slider) SetValue(v float64) {
+	if s.dragging {
+		return
+	}
+	s.value = clamp01(v)
 }
 
-func (s *Slider) GetValue() int {
-	return s.current
+func (s *Slider) GetValue() float64 {
+	return s.value
+}
+
+func (s *Slider) SetLabel(l string) {
+	s.label = l
+}
+
+// SetTicks sets the marks(0.0-1.0) drawn on the bar.
+func (s *Slider) SetTicks(ticks []float64) {
+	s.ticks = ticks
 }
 
 func (s *Slider) Move(x, y int) error {
-	return fmt.Errorf("Not Implemented")
+	s.x = x
+	s.y = y
+	return nil
 }
 
 func (s *Slider) Point() (float64, float64) {
-	log.Println("Slider Point Not Implemented")
-	return -1, -1
+	return float64(s.x), float64(s.y)
+}
+
+func (s *Slider) rect() image.Rectangle {
+	return image.Rect(s.x, s.y+int(dpf(SliderCurrentY)),
+		s.x+s.width, s.y+int(dpf(SliderCurrentY)+dpf(SliderCurrentHeight)))
 }
 
 func (s *Slider) In(x, y int) bool {
-	return image.Pt(x, y).In(s.rect)
+	return image.Pt(x, y).In(s.rect())
+}
+
+func (s *Slider) valueAt(x int) float64 {
+	return clamp01(float64(x-s.x) / (float64(s.width) - dpf(SliderCurrentWidth)))
+}
+
+func clamp01(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
+
+// Preview returns the value under the cursor while hovering or dragging.
+func (s *Slider) Preview() (float64, bool) {
+	if s.dragging {
+		return s.value, true
+	}
+	return s.hoverV, s.hover
+}
+
+// PreviewX is the x of the value.
+func (s *Slider) PreviewX(v float64) int {
+	return s.x + int(v*(float64(s.width)-dpf(SliderCurrentWidth))+dpf(SliderCurrentWidth)/2)
 }
 
 func (s *Slider) Update(x, y int) error {
 
-	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
-		if s.In(x, y) {
-			v := int((float64(x)-SliderStartX)/(SliderWidth-SliderCurrentWidth)*(float64(s.max)-1.0)) + 1
-			s.changeFunc(v)
-			s.current = v
+	s.hover = s.In(x, y)
+	if s.hover {
+		s.hoverV = s.valueAt(x)
+	}
+
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) && s.hover {
+		s.dragging = true
+	}
+
+	if !s.dragging {
+		return nil
+	}
+
+	//while dragging, follow the cursor outside of the slider
+	cx, _ := ebiten.CursorPosition()
+	s.value = s.valueAt(cx)
+
+	if !ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		s.dragging = false
+		if s.changeFunc != nil {
+			err := s.changeFunc(s.value)
+			if err != nil {
+				return xerrors.Errorf("changeFunc() error: %w", err)
+			}
 		}
 	}
 	return nil
 }
 
-const (
-	SliderStartX        = 20.0
-	SliderWidth         = 500.0
-	SliderCurrentY      = 30.0
-	SliderCurrentWidth  = 10.0
-	SliderCurrentHeight = 15.0
-)
-
 func (s *Slider) Draw(img *ebiten.Image) error {
-	x, y, w, h := SliderStartX, SliderCurrentY+5.0, SliderWidth, 5.0
-	ebitenutil.DrawRect(img, x, y, w, h, color.White)
 
-	p := 0.0
-	if s.max != 1 {
-		p = float64(s.current-1) / float64(s.max-1)
+	x, y, w := float64(s.x), float64(s.y)+dpf(SliderCurrentY), float64(s.width)
+	ebitenutil.DrawRect(img, x, y+dpf(5), w, dpf(5), theme.Slider)
+
+	span := w - dpf(SliderCurrentWidth)
+	for _, t := range s.ticks {
+		tx := x + span*t + dpf(SliderCurrentWidth)/2
+		ebitenutil.DrawRect(img, tx-dpf(1), y, dpf(2), dpf(SliderCurrentHeight), theme.Button)
 	}
-	currentP := (SliderWidth-SliderCurrentWidth)*p + SliderStartX
 
-	cx, cy, cw, ch := currentP, SliderCurrentY, SliderCurrentWidth, SliderCurrentHeight
-	ebitenutil.DrawRect(img, cx, cy, cw, ch, color.White)
+	cx := x + span*s.value
+	ebitenutil.DrawRect(img, cx, y, dpf(SliderCurrentWidth), dpf(SliderCurrentHeight), theme.Slider)
 
-	tx := SliderStartX + SliderWidth + 10
+	tx := s.x + s.width + dp(10)
+	text.Draw(img, s.label, defaultFont, tx, int(y+dpf(SliderCurrentHeight)), theme.Text)
 
-	text.Draw(img, fmt.Sprintf("%d/%d", s.current, s.max), defaultFont,
-		int(tx), SliderCurrentY+SliderCurrentHeight, color.White)
+	return nil
+}
+
+// SliderPreview loads the thumbnails for the slider preview in the background.
+type SliderPreview struct {
+	cache    *ThumbnailCache
+	textures map[string]*ebiten.Image
+
+	mutex   sync.Mutex
+	loading map[string]bool
+	loaded  map[string]image.Image
+}
+
+func NewSliderPreview() *SliderPreview {
+	var p SliderPreview
+	p.cache = NewThumbnailCache(dp(OverviewCellWidth), dp(OverviewCellHeight)-dp(OverviewLabelSpace))
+	p.textures = make(map[string]*ebiten.Image)
+	p.loading = make(map[string]bool)
+	p.loaded = make(map[string]image.Image)
+	return &p
+}
 
+// Get returns the thumbnail, nil while loading.
+func (p *SliderPreview) Get(name string) *ebiten.Image {
+
+	if tex, ok := p.textures[name]; ok {
+		return tex
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if img, ok := p.loaded[name]; ok {
+		tex := ebiten.NewImageFromImage(img)
+		p.textures[name] = tex
+		delete(p.loaded, name)
+		return tex
+	}
+
+	if !p.loading[name] {
+		p.loading[name] = true
+		go func() {
+			img, err := p.cache.Get(name, book.Load)
+			if err != nil {
+				logger.Warn("thumbnail error", F("file", name), F("err", err))
+				return
+			}
+			p.mutex.Lock()
+			p.loaded[name] = img
+			p.mutex.Unlock()
+		}()
+	}
 	return nil
 }
@@ -0,0 +1,333 @@
+package wtv
+
+import (
+	"image"
+	"image/color"
+	"unicode"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font"
+	"golang.org/x/xerrors"
+)
+
+const (
+	KeyRepeatDelay    = 30
+	KeyRepeatInterval = 3
+	TextInputPadding  = 6
+	TextInputBlink    = 30
+)
+
+// clipboard is in the application, ebiten has no clipboard API.
+var clipboard string
+
+// repeatKey is true when the key is pressed and while it is held.
+func repeatKey(k ebiten.Key) bool {
+	d := inpututil.KeyPressDuration(k)
+	if d == 1 {
+		return true
+	}
+	return d >= KeyRepeatDelay && (d-KeyRepeatDelay)%KeyRepeatInterval == 0
+}
+
+// TextInput is a single line text field.
+// The characters are from ebiten.InputChars(), so the IME input is included.
+type TextInput struct {
+	*Rectangle
+
+	text    []rune
+	cursor  int
+	anchor  int
+	scrollX int
+
+	focused  bool
+	hover    bool
+	dragging bool
+	blink    int
+
+	//shown while the text is empty
+	placeholder string
+
+	changeFunc func(string) error
+	submitFunc func(string) error
+}
+
+func NewTextInput(x, y, w, h int) *TextInput {
+	var t TextInput
+	t.Rectangle = NewRectangle(x, y, w, h)
+	return &t
+}
+
+func (t *TextInput) SetPlaceholder(s string) {
+	t.placeholder = s
+}
+
+func (t *TextInput) Set(w, h int) {
+	t.w = w
+	t.h = h
+}
+
+func (t *TextInput) SetText(s string) {
+	t.text = []rune(s)
+	t.cursor = len(t.text)
+	t.anchor = t.cursor
+	t.scrollX = 0
+}
+
+func (t *TextInput) Text() string {
+	return string(t.text)
+}
+
+// Changed is called when the text is edited.
+func (t *TextInput) Changed(fn func(string) error) {
+	t.changeFunc = fn
+}
+
+// Submit is called by the enter key.
+func (t *TextInput) Submit(fn func(string) error) {
+	t.submitFunc = fn
+}
+
+func (t *TextInput) SetFocus(f bool) {
+	t.focused = f
+	if !f {
+		t.anchor = t.cursor
+	}
+}
+
+func (t *TextInput) Focused() bool {
+	return t.focused
+}
+
+func (t *TextInput) selection() (int, int) {
+	if t.anchor < t.cursor {
+		return t.anchor, t.cursor
+	}
+	return t.cursor, t.anchor
+}
+
+func (t *TextInput) selected() string {
+	s, e := t.selection()
+	return string(t.text[s:e])
+}
+
+func (t *TextInput) deleteSelection() bool {
+	s, e := t.selection()
+	if s == e {
+		return false
+	}
+	t.text = append(t.text[:s], t.text[e:]...)
+	t.cursor = s
+	t.anchor = s
+	return true
+}
+
+func (t *TextInput) insert(rs []rune) {
+	t.deleteSelection()
+	buf := make([]rune, 0, len(t.text)+len(rs))
+	buf = append(buf, t.text[:t.cursor]...)
+	buf = append(buf, rs...)
+	buf = append(buf, t.text[t.cursor:]...)
+	t.text = buf
+	t.cursor += len(rs)
+	t.anchor = t.cursor
+}
+
+func textWidth(rs []rune) int {
+	return font.MeasureString(defaultFont, string(rs)).Ceil()
+}
+
+// indexAt returns the rune index at the x in the component.
+func (t *TextInput) indexAt(x int) int {
+	x += t.scrollX - dp(TextInputPadding)
+	for idx := range t.text {
+		left := textWidth(t.text[:idx])
+		right := textWidth(t.text[:idx+1])
+		if x < (left+right)/2 {
+			return idx
+		}
+	}
+	return len(t.text)
+}
+
+// moveCursor moves the cursor, the selection is extended with shift.
+func (t *TextInput) moveCursor(idx int, shift bool) {
+	if idx < 0 {
+		idx = 0
+	}
+	if idx > len(t.text) {
+		idx = len(t.text)
+	}
+	t.cursor = idx
+	if !shift {
+		t.anchor = idx
+	}
+	t.blink = 0
+}
+
+func (t *TextInput) scrollToCursor() {
+	cx := textWidth(t.text[:t.cursor])
+	inner := t.w - dp(TextInputPadding)*2
+	if cx-t.scrollX > inner {
+		t.scrollX = cx - inner
+	}
+	if cx < t.scrollX {
+		t.scrollX = cx
+	}
+}
+
+func (t *TextInput) HandleEvent(e *Event) error {
+
+	switch e.Type {
+	case MouseEnterEvent:
+		t.hover = true
+	case MouseLeaveEvent:
+		t.hover = false
+	case FocusEvent:
+		t.SetFocus(true)
+	case BlurEvent:
+		t.SetFocus(false)
+	case MouseDownEvent:
+		e.Consume()
+		t.moveCursor(t.indexAt(e.X), ebiten.IsKeyPressed(ebiten.KeyShift))
+		t.dragging = true
+	case MouseUpEvent:
+		t.dragging = false
+	case KeyDownEvent:
+		if e.Key != ebiten.KeyEnter {
+			return nil
+		}
+		e.Consume()
+		if t.submitFunc != nil {
+			err := t.submitFunc(t.Text())
+			if err != nil {
+				return xerrors.Errorf("submitFunc() error: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+func (t *TextInput) Update(x, y int) error {
+
+	if t.hover {
+		ebiten.SetCursorShape(ebiten.CursorShapeText)
+	}
+	if t.dragging {
+		t.moveCursor(t.indexAt(x-t.x), true)
+	}
+	if !t.focused {
+		return nil
+	}
+	t.blink++
+
+	changed := false
+	shift := ebiten.IsKeyPressed(ebiten.KeyShift)
+
+	if ebiten.IsKeyPressed(ebiten.KeyControl) {
+		switch {
+		case inpututil.IsKeyJustPressed(ebiten.KeyA):
+			t.anchor = 0
+			t.cursor = len(t.text)
+		case inpututil.IsKeyJustPressed(ebiten.KeyC):
+			clipboard = t.selected()
+		case inpututil.IsKeyJustPressed(ebiten.KeyX):
+			clipboard = t.selected()
+			changed = t.deleteSelection()
+		case inpututil.IsKeyJustPressed(ebiten.KeyV):
+			t.insert([]rune(clipboard))
+			changed = clipboard != ""
+		}
+	} else {
+		var rs []rune
+		for _, r := range ebiten.InputChars() {
+			if unicode.IsPrint(r) {
+				rs = append(rs, r)
+			}
+		}
+		if len(rs) > 0 {
+			t.insert(rs)
+			changed = true
+		}
+	}
+
+	switch {
+	case repeatKey(ebiten.KeyBackspace):
+		if t.deleteSelection() {
+			changed = true
+		} else if t.cursor > 0 {
+			t.text = append(t.text[:t.cursor-1], t.text[t.cursor:]...)
+			t.moveCursor(t.cursor-1, false)
+			changed = true
+		}
+	case repeatKey(ebiten.KeyDelete):
+		if t.deleteSelection() {
+			changed = true
+		} else if t.cursor < len(t.text) {
+			t.text = append(t.text[:t.cursor], t.text[t.cursor+1:]...)
+			changed = true
+		}
+	case repeatKey(ebiten.KeyLeft):
+		t.moveCursor(t.cursor-1, shift)
+	case repeatKey(ebiten.KeyRight):
+		t.moveCursor(t.cursor+1, shift)
+	case inpututil.IsKeyJustPressed(ebiten.KeyHome):
+		t.moveCursor(0, shift)
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnd):
+		t.moveCursor(len(t.text), shift)
+	}
+
+	t.scrollToCursor()
+
+	if changed && t.changeFunc != nil {
+		err := t.changeFunc(t.Text())
+		if err != nil {
+			return xerrors.Errorf("changeFunc() error: %w", err)
+		}
+	}
+	return nil
+}
+
+func (t *TextInput) innerRect() image.Rectangle {
+	return image.Rect(t.x+1, t.y+1, t.x+t.w-1, t.y+t.h-1)
+}
+
+func (t *TextInput) Draw(img *ebiten.Image) error {
+
+	x, y := float64(t.x), float64(t.y)
+	w, h := float64(t.w), float64(t.h)
+
+	border := color.Color(theme.Button)
+	if t.focused {
+		border = theme.Active
+	}
+	ebitenutil.DrawRect(img, x, y, w, h, border)
+	ebitenutil.DrawRect(img, x+1, y+1, w-2, h-2, theme.Surface)
+
+	//the text is clipped by the inner image
+	inner := img.SubImage(t.innerRect()).(*ebiten.Image)
+
+	tx := t.x + dp(TextInputPadding) - t.scrollX
+	ty := t.y + (t.h+defaultFont.Metrics().XHeight.Ceil())/2
+
+	s, e := t.selection()
+	if s != e {
+		sx := tx + textWidth(t.text[:s])
+		ex := tx + textWidth(t.text[:e])
+		ebitenutil.DrawRect(inner, float64(sx), y+dpf(4), float64(ex-sx), h-dpf(8), theme.Selection)
+	}
+
+	if len(t.text) == 0 && t.placeholder != "" {
+		text.Draw(inner, t.placeholder, defaultFont, tx, ty, theme.Placeholder)
+	} else {
+		text.Draw(inner, string(t.text), defaultFont, tx, ty, theme.Text)
+	}
+
+	if t.focused && (t.blink/TextInputBlink)%2 == 0 {
+		cx := tx + textWidth(t.text[:t.cursor])
+		ebitenutil.DrawRect(inner, float64(cx), y+dpf(4), dpf(1), h-dpf(8), theme.Text)
+	}
+	return nil
+}
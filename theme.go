@@ -0,0 +1,199 @@
+package wtv
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"os"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	DarkThemeName  = "dark"
+	LightThemeName = "light"
+)
+
+// Color is color.RGBA written as "#rrggbb" or "#rrggbbaa" in the theme file.
+type Color color.RGBA
+
+func (c Color) RGBA() (uint32, uint32, uint32, uint32) {
+	return color.RGBA(c).RGBA()
+}
+
+func (c Color) MarshalJSON() ([]byte, error) {
+	return json.Marshal(colorHex(color.RGBA(c)))
+}
+
+func (c *Color) UnmarshalJSON(data []byte) error {
+	var v string
+	err := json.Unmarshal(data, &v)
+	if err != nil {
+		return xerrors.Errorf("json.Unmarshal() error: %w", err)
+	}
+	clr, err := parseColorHex(v)
+	if err != nil {
+		return xerrors.Errorf("parseColorHex() error: %w", err)
+	}
+	*c = Color(clr)
+	return nil
+}
+
+// Theme is the look of the components.
+type Theme struct {
+	Name string
+
+	Background Color
+	Header     Color
+	//fields and lists
+	Surface Color
+	//cells and the rows under the cursor
+	Item      Color
+	Selection Color
+	Text      Color
+	Error     Color
+	//behind the overlay
+	Shade Color
+
+	Button     Color
+	ButtonText Color
+	Active     Color
+
+	MenuBackground Color
+	MenuArrow      Color
+	Scrollbar      Color
+	Slider         Color
+	Placeholder    Color
+
+	DebugBackground Color
+	DebugText       Color
+
+	FontSize     float64
+	CornerRadius int
+	//opacity of the menu
+	AreaOpacity   float64
+	ActiveOpacity float64
+}
+
+// theme is copied by SetTheme, pointers to the fields follow the change.
+var theme = *DarkTheme()
+
+// themeGeneration is counted up to draw the cached images again.
+var themeGeneration int
+
+func DarkTheme() *Theme {
+	return &Theme{
+		Name:            DarkThemeName,
+		Background:      Color{20, 20, 20, 255},
+		Header:          Color{0, 0, 0, 255},
+		Surface:         Color{20, 20, 20, 255},
+		Item:            Color{40, 40, 40, 255},
+		Selection:       Color{60, 90, 160, 255},
+		Text:            Color{255, 255, 255, 255},
+		Error:           Color{255, 80, 80, 255},
+		Shade:           Color{0, 0, 0, 200},
+		Button:          Color{128, 128, 128, 255},
+		ButtonText:      Color{0, 0, 0, 255},
+		Active:          Color{230, 180, 0, 255},
+		MenuBackground:  Color{0, 0, 0, 255},
+		MenuArrow:       Color{200, 200, 200, 255},
+		Scrollbar:       Color{30, 30, 30, 255},
+		Slider:          Color{255, 255, 255, 255},
+		Placeholder:     Color{60, 60, 60, 255},
+		DebugBackground: Color{0, 0, 0, 30},
+		DebugText:       Color{23, 200, 0, 255},
+		FontSize:        defaultFontSize,
+		CornerRadius:    0,
+		AreaOpacity:     0.5,
+		ActiveOpacity:   0.9,
+	}
+}
+
+func LightTheme() *Theme {
+	return &Theme{
+		Name:            LightThemeName,
+		Background:      Color{240, 240, 240, 255},
+		Header:          Color{220, 220, 220, 255},
+		Surface:         Color{255, 255, 255, 255},
+		Item:            Color{225, 225, 225, 255},
+		Selection:       Color{170, 200, 245, 255},
+		Text:            Color{20, 20, 20, 255},
+		Error:           Color{200, 30, 30, 255},
+		Shade:           Color{255, 255, 255, 220},
+		Button:          Color{190, 190, 190, 255},
+		ButtonText:      Color{20, 20, 20, 255},
+		Active:          Color{210, 120, 0, 255},
+		MenuBackground:  Color{250, 250, 250, 255},
+		MenuArrow:       Color{90, 90, 90, 255},
+		Scrollbar:       Color{210, 210, 210, 255},
+		Slider:          Color{60, 60, 60, 255},
+		Placeholder:     Color{200, 200, 200, 255},
+		DebugBackground: Color{255, 255, 255, 60},
+		DebugText:       Color{0, 120, 0, 255},
+		FontSize:        defaultFontSize,
+		CornerRadius:    4,
+		AreaOpacity:     0.6,
+		ActiveOpacity:   0.95,
+	}
+}
+
+// LoadTheme returns the built-in theme or reads the file(JSON).
+// The fields missing in the file are the dark theme.
+func LoadTheme(name string) (*Theme, error) {
+
+	switch strings.ToLower(name) {
+	case "", DarkThemeName:
+		return DarkTheme(), nil
+	case LightThemeName:
+		return LightTheme(), nil
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, xerrors.Errorf("os.ReadFile() error: %w", err)
+	}
+
+	t := DarkTheme()
+	err = json.Unmarshal(data, t)
+	if err != nil {
+		return nil, xerrors.Errorf("json.Unmarshal() error: %w", err)
+	}
+	//the name is the file to compare with the config
+	t.Name = name
+	return t, nil
+}
+
+func SetTheme(t *Theme) error {
+	err := setFontSize(t.FontSize)
+	if err != nil {
+		return xerrors.Errorf("setFontSize() error: %w", err)
+	}
+	theme = *t
+	themeGeneration++
+	return nil
+}
+
+func colorHex(c color.RGBA) string {
+	if c.A != 255 {
+		return fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A)
+	}
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func parseColorHex(v string) (color.RGBA, error) {
+	c := color.RGBA{A: 255}
+	var err error
+	switch len(v) {
+	case 7:
+		_, err = fmt.Sscanf(v, "#%02x%02x%02x", &c.R, &c.G, &c.B)
+	case 9:
+		_, err = fmt.Sscanf(v, "#%02x%02x%02x%02x", &c.R, &c.G, &c.B, &c.A)
+	default:
+		return c, xerrors.Errorf("invalid color[%s] (#rrggbb or #rrggbbaa)", v)
+	}
+	if err != nil {
+		return c, xerrors.Errorf("invalid color[%s] (#rrggbb or #rrggbbaa)", v)
+	}
+	return c, nil
+}
@@ -0,0 +1,102 @@
+package wtv
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"sync"
+	"wtv/book"
+	"wtv/config"
+
+	"golang.org/x/xerrors"
+)
+
+const ThumbnailDirectory = "thumbnail"
+
+// ThumbnailCache keeps thumbnails in memory and in the cache directory.
+// The disk cache is keyed by the path, size and modtime of the source.
+type ThumbnailCache struct {
+	width  int
+	height int
+
+	mutex  sync.Mutex
+	memory map[string]image.Image
+}
+
+func NewThumbnailCache(w, h int) *ThumbnailCache {
+	var c ThumbnailCache
+	c.width = w
+	c.height = h
+	c.memory = make(map[string]image.Image)
+	return &c
+}
+
+func (c *ThumbnailCache) path(name string) (string, error) {
+
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return "", xerrors.Errorf("filepath.Abs() error: %w", err)
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return "", xerrors.Errorf("os.Stat() error: %w", err)
+	}
+
+	key := fmt.Sprintf("%s:%d:%d", abs, info.Size(), info.ModTime().UnixNano())
+	fn := fmt.Sprintf("%x_%dx%d.jpg", sha1.Sum([]byte(key)), c.width, c.height)
+
+	return filepath.Join(config.CacheDir(), ThumbnailDirectory, fn), nil
+}
+
+// Get returns the thumbnail of the name.
+// load is called to decode the source when the thumbnail is not cached.
+func (c *ThumbnailCache) Get(name string, load func(string) (image.Image, error)) (image.Image, error) {
+
+	c.mutex.Lock()
+	img, ok := c.memory[name]
+	c.mutex.Unlock()
+	if ok {
+		debugStats.Cache("thumbnail", "memory")
+		return img, nil
+	}
+
+	path, err := c.path(name)
+	if err != nil {
+		return nil, xerrors.Errorf("path() error: %w", err)
+	}
+
+	img, err = book.Load(path)
+	if err == nil {
+		debugStats.Cache("thumbnail", "disk")
+	} else {
+		debugStats.Cache("thumbnail", "miss")
+		src, err := load(name)
+		if err != nil {
+			return nil, xerrors.Errorf("load() error: %w", err)
+		}
+		img = book.Thumbnail(src, c.width, c.height)
+
+		err = os.MkdirAll(filepath.Dir(path), 0777)
+		if err != nil {
+			return nil, xerrors.Errorf("os.MkdirAll() error: %w", err)
+		}
+		err = book.WriteImage(path, img)
+		if err != nil {
+			return nil, xerrors.Errorf("WriteImage() error: %w", err)
+		}
+	}
+
+	c.mutex.Lock()
+	c.memory[name] = img
+	c.mutex.Unlock()
+
+	return img, nil
+}
+
+func (c *ThumbnailCache) Clear() {
+	c.mutex.Lock()
+	c.memory = make(map[string]image.Image)
+	c.mutex.Unlock()
+}
@@ -0,0 +1,114 @@
+package wtv
+
+import (
+	"image/color"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+const (
+	ToastDuration = 240 //ticks
+	ToastFade     = 30
+	ToastMax      = 4
+	ToastHeight   = 32
+	ToastMargin   = 10
+	ToastWidth    = 600
+)
+
+// Toast is a message of the recoverable error.
+type Toast struct {
+	Message string
+	Level   Level
+	ticks   int
+}
+
+// Toasts are shown at the bottom of the screen over the scenes.
+// Notify can be called by the loaders in the background.
+type Toasts struct {
+	mutex sync.Mutex
+	list  []*Toast
+}
+
+var toasts = NewToasts()
+
+func NewToasts() *Toasts {
+	var t Toasts
+	return &t
+}
+
+// Notify shows the message, the same message only extends the time.
+func (t *Toasts) Notify(level Level, msg string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for _, elm := range t.list {
+		if elm.Message == msg {
+			elm.ticks = ToastDuration
+			return
+		}
+	}
+
+	t.list = append(t.list, &Toast{msg, level, ToastDuration})
+	if len(t.list) > ToastMax {
+		t.list = t.list[len(t.list)-ToastMax:]
+	}
+}
+
+func (t *Toasts) Update() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	list := t.list[:0]
+	for _, elm := range t.list {
+		elm.ticks--
+		if elm.ticks > 0 {
+			list = append(list, elm)
+		}
+	}
+	t.list = list
+}
+
+// Draw stacks the toasts up from the bottom, the newest is at the bottom.
+func (t *Toasts) Draw(screen *ebiten.Image, w, h int) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	th := dp(ToastHeight)
+	y := h - dp(ToastMargin)
+	for idx := len(t.list) - 1; idx >= 0; idx-- {
+		elm := t.list[idx]
+		y -= th
+
+		a := 1.0
+		if elm.ticks < ToastFade {
+			a = float64(elm.ticks) / ToastFade
+		}
+
+		msg := fitText(elm.Message, dp(ToastWidth))
+		tw := text.BoundString(defaultFont, msg).Dx() + dp(ToastMargin)*2
+		x := (w - tw) / 2
+		ebitenutil.DrawRect(screen, float64(x), float64(y), float64(tw), float64(th), fade(theme.Shade, a))
+
+		clr := theme.Text
+		if elm.Level >= ErrorLevel {
+			clr = theme.Error
+		}
+		ty := y + (th+defaultFont.Metrics().CapHeight.Ceil())/2
+		text.Draw(screen, msg, defaultFont, x+dp(ToastMargin), ty, fade(clr, a))
+
+		y -= dp(ToastMargin) / 2
+	}
+}
+
+// fade multiplies the premultiplied color by the opacity.
+func fade(c Color, a float64) color.Color {
+	return color.RGBA{
+		R: uint8(float64(c.R) * a),
+		G: uint8(float64(c.G) * a),
+		B: uint8(float64(c.B) * a),
+		A: uint8(float64(c.A) * a),
+	}
+}
@@ -1,14 +1,14 @@
 package wtv
 
 import (
-	"errors"
 	"fmt"
 	"image"
-	"log"
-	"path/filepath"
-	"sync"
+	"wtv/book"
+	"wtv/config"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/text"
 	"golang.org/x/xerrors"
 )
 
@@ -19,20 +19,22 @@ const (
 	AutoPlayMode
 )
 
+// Viewer shows the book as one long strip.
+// The position is the global offset of the Document at the viewer width,
+// index and pos are the page and the position in the page of it.
 type Viewer struct {
-	book *Book
+	book *book.Book
 
-	prev    *ebiten.Image
-	current *ebiten.Image
-	next    *ebiten.Image
-	index   int
+	loader   *PageLoader
+	textures map[int]*ebiten.Image
+	failed   map[int]error
 
-	loadingPrev sync.Once
-	loadingNext sync.Once
+	offset int
+	index  int
+	pos    int
 
 	playMode  PlayMode
 	dragState DragState
-	pos       int
 	startPos  int
 
 	width  int
@@ -42,24 +44,19 @@ type Viewer struct {
 func NewViewer() *Viewer {
 	var v Viewer
 	v.playMode = NormalPlayMode
+	v.loader = NewPageLoader(fitWidth)
+	v.clear()
 	return &v
 }
 
 func (v *Viewer) SetBook(dir string) error {
 
-	opti := false
-	if existsOptimizeDirectory(dir) {
-		dir = filepath.Join(dir, OptimizeDirectory)
-		opti = true
-	}
-
-	b, err := NewBook(dir)
+	b, err := book.Open(dir, false)
 	if err != nil {
-		return xerrors.Errorf("NewBook() error: %w", err)
+		return xerrors.Errorf("book.Open() error: %w", err)
 	}
-	b.optimize = opti
 
-	if b.canOptimize(v.width, v.height) {
+	if b.CanOptimize(v.width, v.height) {
 		nb, err := b.Optimize(v.width, v.height)
 		if err != nil {
 			return xerrors.Errorf("Book optimize error: %w", err)
@@ -68,6 +65,8 @@ func (v *Viewer) SetBook(dir string) error {
 	}
 
 	v.book = b
+	v.loader.Set(b, v.width)
+	v.clear()
 
 	err = v.reset()
 	if err != nil {
@@ -78,18 +77,54 @@ func (v *Viewer) SetBook(dir string) error {
 	return nil
 }
 
-func (v *Viewer) GetBook() (*Book, int) {
+func (v *Viewer) GetBook() (*book.Book, int) {
 	return v.book, v.index
 }
 
+// Offset is the global offset of the top of the screen.
+func (v *Viewer) Offset() int {
+	return v.offset
+}
+
+// MaxOffset is the offset that the last page bottom is at the screen bottom.
+func (v *Viewer) MaxOffset() int {
+	if v.book == nil {
+		return 0
+	}
+	max := v.book.Document().Length(v.width) - v.height
+	if max < 0 {
+		return 0
+	}
+	return max
+}
+
+func (v *Viewer) SetOffset(off int) {
+
+	if v.book == nil {
+		return
+	}
+
+	max := v.MaxOffset()
+	if off > max {
+		off = max
+	}
+	if off < 0 {
+		off = 0
+	}
+
+	v.offset = off
+	v.index, v.pos = v.book.Document().Locate(off, v.width)
+}
+
+func (v *Viewer) clear() {
+	v.textures = make(map[int]*ebiten.Image)
+	v.failed = make(map[int]error)
+}
+
 func (v *Viewer) reset() error {
-	v.prev = nil
-	v.current = nil
-	v.next = nil
+	v.offset = 0
 	v.index = 0
 	v.pos = 0
-	v.loadingNext = sync.Once{}
-	v.loadingPrev = sync.Once{}
 	return nil
 }
 
@@ -101,47 +136,92 @@ func (v *Viewer) enable() bool {
 	if v.book == nil {
 		return false
 	}
-	if v.current == nil {
-		return false
-	}
 	return true
 }
 
+// Redraw changes the size of the viewer.
+// The position is kept by scaling the offset to the new width.
 func (v *Viewer) Redraw(w, h int) {
 
-	img, err := v.book.Load(v.index)
-	if err != nil {
-		log.Println(err)
-		return
+	if v.width != 0 && v.width != w {
+		v.offset = int(float64(v.offset) * float64(w) / float64(v.width))
 	}
-
 	v.width, v.height = w, h
-	v.current = v.resize(img)
 
-	//TODO  next prev
-	if v.prev != nil {
-		v.prev = v.resize(v.prev)
-	}
-	if v.next != nil {
-		v.next = v.resize(v.next)
+	if v.loader.Set(v.book, w) {
+		v.clear()
 	}
-
-	return
+	v.SetOffset(v.offset)
 }
 
-func (v *Viewer) resize(src image.Image) *ebiten.Image {
-	s := float64(v.width) / float64(src.Bounds().Dx())
+// fitWidth scales the image to the width.
+// Due to height restrictions the image may be smaller, it is stretched when drawn.
+func fitWidth(src image.Image, width int) image.Image {
+	s := float64(width) / float64(src.Bounds().Dx())
 	h := float64(src.Bounds().Dy())
 
-	if (h * s) > OpenGLHeight {
+	if (h * s) > book.OpenGLHeight {
 		orgS := s
-		s = float64(OpenGLHeight) / float64(h)
-		fmt.Printf("Due to height restrictions,the magnification will be changed\n%0.2f -> %0.2f\n", orgS, s)
+		s = float64(book.OpenGLHeight) / float64(h)
+		logger.Info("magnification changed by the height restriction",
+			F("from", fmt.Sprintf("%0.2f", orgS)), F("to", fmt.Sprintf("%0.2f", s)))
 	}
 
-	dst := Scale(src, s)
+	return book.Scale(src, s)
+}
 
-	return ebiten.NewImageFromImage(dst)
+// each calls fn with the visible pages and the top on the screen.
+func (v *Viewer) each(fn func(idx, y, ph int)) {
+	doc := v.book.Document()
+	idx, pos := doc.Locate(v.offset, v.width)
+	for y := -pos; idx < doc.Pages() && y < v.height; idx++ {
+		ph := doc.PageHeight(idx, v.width)
+		fn(idx, y, ph)
+		y += ph
+	}
+}
+
+func (v *Viewer) load() {
+
+	for _, page := range v.loader.Collect() {
+		if page.err != nil {
+			v.failed[page.index] = page.err
+			logger.Error("page load error", F("index", page.index), F("err", page.err))
+			toasts.Notify(WarnLevel, fmt.Sprintf("page %d failed to decode — skipped (P: problems)", page.index+1))
+			continue
+		}
+		v.textures[page.index] = ebiten.NewImageFromImage(page.img)
+	}
+
+	min, max := -1, -1
+	v.each(func(idx, y, ph int) {
+		if min == -1 {
+			min = idx
+		}
+		max = idx
+	})
+	if min == -1 {
+		return
+	}
+	prefetch := config.Get().Prefetch
+	min -= prefetch
+	max += prefetch
+
+	for idx := min; idx <= max; idx++ {
+		if idx < 0 || idx >= v.book.Page() {
+			continue
+		}
+		if _, ok := v.textures[idx]; ok || v.failed[idx] != nil {
+			continue
+		}
+		v.loader.Request(idx)
+	}
+
+	for idx := range v.textures {
+		if idx < min || idx > max {
+			delete(v.textures, idx)
+		}
+	}
 }
 
 func (v *Viewer) Update() error {
@@ -150,18 +230,15 @@ func (v *Viewer) Update() error {
 		return nil
 	}
 
-	v.loadPrev()
-	v.loadNext()
+	v.load()
 
 	if v.playMode == AutoPlayMode {
-		v.pos += 5
-		fmt.Printf("\r%10d", v.pos)
+		v.Scroll(dp(config.Get().AutoScrollSpeed))
 		return nil
 	}
 
 	_, nowY := ebiten.CursorPosition()
 
-	b := v.current.Bounds()
 	_, dy := ebiten.Wheel()
 
 	v.dragState = v.dragState.Get()
@@ -172,121 +249,128 @@ func (v *Viewer) Update() error {
 
 		my := v.startPos - nowY
 		if dy != 0 {
-			my = int(dy * 80 * -1)
+			my = int(dy * dpf(80) * -1)
 		}
 
-		v.pos, v.startPos = v.pos+my, nowY
-		m := 0
-
-		if v.pos < m {
-			if v.prev == nil {
-				v.pos = 0
-			}
-		} else if v.pos > b.Dy()-v.height-m {
-			if v.next == nil {
-				v.pos = b.Dy() - v.height
-			}
-		}
+		v.startPos = nowY
+		v.Scroll(my)
 	}
 
 	return nil
 }
 
-func (v *Viewer) loadPrev() error {
-
-	if v.prev != nil {
-		return nil
+func (v *Viewer) Scroll(dy int) {
+	if !v.enable() {
+		return
 	}
-
-	go v.loadingPrev.Do(func() {
-
-		img, err := v.book.Load(v.index - 1)
-		if err != nil {
-			if !errors.Is(err, BookIndexError) {
-				log.Println(err)
-			}
-			return
-		}
-		v.prev = v.resize(img)
-	})
-	return nil
+	v.SetOffset(v.offset + dy)
 }
 
-func (v *Viewer) loadNext() error {
+func (v *Viewer) Jump(idx, pos int) {
 
-	if v.next != nil {
-		return nil
+	if v.book == nil {
+		return
 	}
 
-	go v.loadingNext.Do(func() {
-
-		img, err := v.book.Load(v.index + 1)
-		if err != nil {
-			if !errors.Is(err, BookIndexError) {
-				log.Println(err)
-			}
-			return
-		}
-		v.next = v.resize(img)
-	})
+	if idx >= v.book.Page() {
+		idx = v.book.Page() - 1
+	}
+	if idx < 0 {
+		idx = 0
+	}
 
-	return nil
+	v.SetOffset(v.book.Document().Offset(idx, pos, v.width))
 }
 
-func (v *Viewer) Draw(screen *ebiten.Image) {
+// Sort changes the page order, the current page is kept.
+func (v *Viewer) Sort(t config.SortType) {
 
-	if !v.enable() {
+	if v.book == nil {
 		return
 	}
 
-	op := &ebiten.DrawImageOptions{}
-	py := float64(v.pos * -1)
-	op.GeoM.Translate(0, py)
-	screen.DrawImage(v.current, op)
+	name := v.book.File(v.index)
+	pos := v.pos
 
-	v.drawPrev(screen, py)
-	v.drawNext(screen, py)
-}
+	b := v.book.Sorted(t)
+	v.book = b
+	v.loader.Set(b, v.width)
+	v.clear()
 
-func (v *Viewer) drawPrev(screen *ebiten.Image, by float64) {
+	idx := b.Index(name)
+	if idx == -1 {
+		idx, pos = 0, 0
+	}
+	v.Jump(idx, pos)
+}
 
-	if v.prev == nil {
+func (v *Viewer) PrevChapter() {
+	if v.book == nil {
 		return
 	}
+	c := v.book.Chapter(v.index)
+	if c == v.index && v.pos <= 0 {
+		c = v.book.Chapter(c - 1)
+	}
+	v.Jump(c, 0)
+}
 
-	op := &ebiten.DrawImageOptions{}
-	iy := v.prev.Bounds().Dy() * -1
-	ty := iy + int(by)
-	op.GeoM.Translate(0, float64(ty))
-	screen.DrawImage(v.prev, op)
-
-	if ty > iy+v.height {
-		go func() {
-			v.current, v.next, v.prev = v.prev, v.current, nil
-			v.pos = ty * -1
-			v.index--
-			v.loadingPrev = sync.Once{}
-		}()
+func (v *Viewer) NextChapter() {
+	if v.book == nil {
+		return
+	}
+	for _, c := range v.book.Chapters() {
+		if c > v.index {
+			v.Jump(c, 0)
+			return
+		}
 	}
 }
 
-func (v *Viewer) drawNext(screen *ebiten.Image, by float64) {
+func (v *Viewer) Draw(screen *ebiten.Image) {
 
-	if v.next == nil {
+	if !v.enable() {
 		return
 	}
 
-	op := &ebiten.DrawImageOptions{}
-	ty := v.current.Bounds().Dy() + int(by)
-	op.GeoM.Translate(0, float64(ty))
-	screen.DrawImage(v.next, op)
+	v.each(func(idx, y, ph int) {
+		if v.failed[idx] != nil {
+			v.drawPlaceholder(screen, idx, y, ph)
+			return
+		}
+		tex, ok := v.textures[idx]
+		if !ok {
+			return
+		}
+		b := tex.Bounds()
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Scale(float64(v.width)/float64(b.Dx()), float64(ph)/float64(b.Dy()))
+		op.GeoM.Translate(0, float64(y))
+		screen.DrawImage(tex, op)
+	})
+}
+
+// drawPlaceholder is drawn in the size of the broken page to continue reading.
+// It shows the file and the cause.
+func (v *Viewer) drawPlaceholder(screen *ebiten.Image, idx, y, ph int) {
+	m := dp(10)
+	ebitenutil.DrawRect(screen, float64(m), float64(y+m), float64(v.width-m*2), float64(ph-m*2), theme.Placeholder)
 
-	if ty < 0 {
-		go func() {
-			v.current, v.prev, v.next = v.next, v.current, nil
-			v.pos = ty * -1
-			v.index++
-			v.loadingNext = sync.Once{}
-		}()
+	lines := []string{
+		fmt.Sprintf("page %d failed to decode", idx+1),
+		v.book.Name(idx),
+		book.CauseOf(v.failed[idx]).Error(),
+	}
+
+	lh := defaultFont.Metrics().Height.Ceil()
+	ty := y + ph/2 - lh*(len(lines)-1)/2
+	for i, line := range lines {
+		msg := fitText(line, v.width-m*4)
+		tw := text.BoundString(defaultFont, msg).Dx()
+		clr := theme.Text
+		if i == 0 {
+			clr = theme.Error
+		}
+		text.Draw(screen, msg, defaultFont, (v.width-tw)/2, ty+lh*i, clr)
 	}
 }
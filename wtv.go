@@ -7,31 +7,57 @@ import (
 	"golang.org/x/xerrors"
 )
 
-const (
-	OptimizeDirectory = ".wtv_optimize"
-	//height (65536) must be less than or equal to 32768
-	//TODO  -2 means -1 is ebiten error
-	OpenGLHeight   = 1<<(16-1) - 2
-	OptimizeHeight = 1 << 11           //2048
-	OptimizeLimit  = OpenGLHeight >> 2 // 9
-)
-
 func Show() error {
 
-	err := config.Load()
+	lf, err := OpenLogFile()
+	if err != nil {
+		//stderr is used without the file
+		logger.Warn("log file error", F("err", err))
+	} else {
+		defer func() {
+			logger.RemoveSink("file")
+			lf.Close()
+		}()
+	}
+
+	err = config.Load()
 	if err != nil {
 		return xerrors.Errorf("config.Load() error: %w", err)
 	}
 
 	conf := config.Get()
 
+	initScale()
+	err = fonts.Load(conf.Fonts)
+	if err != nil {
+		//the system fonts are used without the user fonts
+		logger.Warn("user font error", F("files", conf.Fonts), F("err", err))
+		toasts.Notify(WarnLevel, "the user fonts could not be loaded")
+		fonts.Load(nil)
+	}
+
+	t, err := LoadTheme(conf.Theme)
+	if err != nil {
+		//the broken theme file does not stop the viewer
+		logger.Warn("theme error", F("theme", conf.Theme), F("err", err))
+		toasts.Notify(WarnLevel, "the theme could not be loaded, dark is used")
+		t = DarkTheme()
+	}
+	err = SetTheme(t)
+	if err != nil {
+		return xerrors.Errorf("SetTheme() error: %w", err)
+	}
+
 	ebiten.SetWindowTitle("Webtoon Viewer")
 	ebiten.SetWindowSize(conf.Width, conf.Height)
 	ebiten.SetWindowResizable(true)
 
-	p := NewPlayer()
+	m, err := NewSceneManager(NewPlayer())
+	if err != nil {
+		return xerrors.Errorf("NewSceneManager() error: %w", err)
+	}
 
-	err = ebiten.RunGame(p)
+	err = ebiten.RunGame(m)
 	if err != nil {
 		return xerrors.Errorf("ebiten.RunGame() error: %w", err)
 	}